@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"go.resystems.io/task-graph/taskgraph"
+)
+
+var (
+	critical_json bool = false
+)
+
+func init() {
+	rootCmd.AddCommand(criticalPathCmd)
+
+	criticalPathCmd.Flags().BoolVar(&critical_json, "json", false, "write the path, its effort and the slack of each issue as JSON")
+	add_snapshot_flag(criticalPathCmd)
+	add_estimate_flags(criticalPathCmd)
+}
+
+// critical_title is the title of an issue, if it was fetched.
+func critical_title(tg *taskgraph.TaskGraph, ref string) string {
+	if t, ok := tg.Refs[ref]; ok {
+		return t.Title
+	}
+	return "(not fetched)"
+}
+
+var criticalPathCmd = &cobra.Command{
+	Use:   "critical-path",
+	Short: "report the longest chain of open issues.",
+	Long: `Fetch tasklists embedded in a root issue
+and report the longest chain of open issues through them,
+for release planning.
+
+Issues are counted, or with --weighted weighed by their
+estimates. The slack of an issue is how much longer the
+longest chain through it could grow before it would be
+critical. Use mermaid --critical-path to highlight the path.
+
+# Example
+
+task-graph -o resystems-io -r architecture -n 8 critical-path --weighted
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := traversal_context()
+		defer cancel()
+
+		// accumulate linked issues
+		tg, err := load_graph(ctx)
+		if err != nil {
+			panic(err)
+		}
+		cp := tg.CriticalPath(render_estimate_fields())
+
+		if critical_json {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(cp); err != nil {
+				panic(err)
+			}
+			return
+		}
+
+		fmt.Fprintf(os.Stdout, "critical path: %d issues", len(cp.Path))
+		if render_weighted {
+			fmt.Fprintf(os.Stdout, ", %s estimated", strconv.FormatFloat(cp.Effort, 'f', -1, 64))
+		}
+		fmt.Fprintf(os.Stdout, "\n\n")
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		for i, ref := range cp.Path {
+			fmt.Fprintf(w, "%d.\t%s\t%s\n", i+1, ref, critical_title(tg, ref))
+		}
+		w.Flush()
+
+		// the other open issues, the most critical first
+		onPath := make(map[string]bool, len(cp.Path))
+		for _, ref := range cp.Path {
+			onPath[ref] = true
+		}
+		var others []string
+		for ref := range cp.Slack {
+			if !onPath[ref] {
+				others = append(others, ref)
+			}
+		}
+		if len(others) == 0 {
+			return
+		}
+		sort.Slice(others, func(i, j int) bool {
+			a, b := cp.Slack[others[i]], cp.Slack[others[j]]
+			return a < b || (a == b && others[i] < others[j])
+		})
+		fmt.Fprintf(os.Stdout, "\nslack:\n\n")
+		for _, ref := range others {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", strconv.FormatFloat(cp.Slack[ref], 'f', -1, 64), ref, critical_title(tg, ref))
+		}
+		w.Flush()
+	},
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"go.resystems.io/task-graph/taskgraph"
+)
+
+var (
+	csv_output       string   = ""
+	csv_edges_output string   = ""
+	csv_skip_closed  bool     = false
+	csv_tsv          bool     = false
+	csv_json         bool     = false
+	csv_columns      []string = taskgraph.DefaultNodeColumns
+	csv_edge_columns []string = taskgraph.DefaultEdgeColumns
+)
+
+func init() {
+	rootCmd.AddCommand(csvCmd)
+
+	csvCmd.Flags().StringVarP(&csv_output, "output", "o", "", "write the issues to this file instead of stdout")
+	csvCmd.Flags().StringVar(&csv_edges_output, "edges-output", "", "also write the edges to this file")
+	csvCmd.Flags().BoolVarP(&csv_skip_closed, "skip-closed", "c", false, "skip traversing closed issues")
+	csvCmd.Flags().BoolVar(&csv_tsv, "tsv", false, "separate fields with tabs rather than commas")
+	csvCmd.Flags().BoolVar(&csv_json, "json", false, "write the tables as JSON arrays of objects rather than CSV")
+	csvCmd.Flags().StringSliceVar(&csv_columns, "columns", taskgraph.DefaultNodeColumns,
+		"columns of the issue table, from: "+strings.Join(taskgraph.NodeColumns(), ", "))
+	csvCmd.Flags().StringSliceVar(&csv_edge_columns, "edge-columns", taskgraph.DefaultEdgeColumns,
+		"columns of the edge table, from: "+strings.Join(taskgraph.EdgeColumns(), ", "))
+	add_snapshot_flag(csvCmd)
+	add_render_flags(csvCmd)
+}
+
+var csvCmd = &cobra.Command{
+	Use:   "csv",
+	Short: "export the tasks as CSV tables.",
+	Long: `Fetch tasklists embedded in a root issue
+and export them as CSV tables, for spreadsheets.
+
+The issue table has a row per issue, in tasklist order, with
+its parents and its depth below the roots. The edge table,
+written with --edges-output, has a row per edge. With --json
+the tables are written as JSON arrays instead.
+
+# Example
+
+task-graph -o resystems-io -r architecture -n 8 csv -o tg-8.csv --edges-output tg-8-edges.csv
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := traversal_context()
+		defer cancel()
+
+		// accumulate linked issues
+		tg, err := load_graph(ctx, taskgraph.WithSkipClosed(csv_skip_closed))
+		if err != nil {
+			panic(err)
+		}
+
+		opts, err := render_options()
+		if err != nil {
+			panic(err)
+		}
+		columns := csv_columns
+		if !cmd.Flags().Changed("columns") {
+			// the default columns, followed by the rollup with --rollup
+			columns = nil
+		}
+		opts = append(opts, taskgraph.WithColumns(columns, csv_edge_columns))
+		if csv_tsv {
+			opts = append(opts, taskgraph.WithSeparator('\t'))
+		}
+
+		writeNodes, writeEdges := tg.WriteCSV, tg.WriteEdgeCSV
+		if csv_json {
+			writeNodes, writeEdges = tg.WriteJSON, tg.WriteEdgeJSON
+		}
+
+		err = write_output(csv_output, func(w io.Writer) error { return writeNodes(w, opts...) })
+		if err != nil {
+			panic(err)
+		}
+		if csv_edges_output != "" {
+			err = write_output(csv_edges_output, func(w io.Writer) error { return writeEdges(w, opts...) })
+			if err != nil {
+				panic(err)
+			}
+		}
+	},
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"go.resystems.io/task-graph/taskgraph"
+)
+
+var (
+	d2_dir         string = ""
+	d2_skip_closed bool   = false
+)
+
+func init() {
+	rootCmd.AddCommand(d2Cmd)
+
+	d2Cmd.Flags().StringVarP(&d2_dir, "dir", "d", "", "flow direction: TB, BT, LR or RL (default TB, or that of the --theme)")
+	d2Cmd.Flags().BoolVarP(&d2_skip_closed, "skip-closed", "c", false, "skip traversing closed issues")
+	add_snapshot_flag(d2Cmd)
+	add_render_flags(d2Cmd)
+	add_group_flag(d2Cmd)
+}
+
+var d2Cmd = &cobra.Command{
+	Use:   "d2",
+	Short: "generate a D2 diagram of tasks.",
+	Long: `Fetch tasklists embedded in a root issue
+and produce a D2 diagram thereof.
+
+# Example
+
+task-graph -o resystems-io -r architecture -n 8 d2 -d LR > tg-8.d2 && d2 tg-8.d2 tg-8.svg
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		render_graph(d2_skip_closed, (*taskgraph.TaskGraph).ToD2,
+			taskgraph.WithDirection(strings.ToUpper(d2_dir)))
+	},
+}
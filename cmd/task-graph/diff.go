@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"go.resystems.io/task-graph/taskgraph"
+)
+
+var (
+	diff_format string = "markdown"
+	diff_dir    string = "TB"
+)
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringVar(&diff_format, "format", "markdown", "output format: markdown, json or mermaid")
+	diffCmd.Flags().StringVarP(&diff_dir, "dir", "d", "TB", "flow direction of mermaid output: TB, BT, LR or RL")
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff old.json new.json",
+	Short: "report what changed between two snapshots.",
+	Long: `Compare two snapshots, written by the snapshot command,
+and report the added and removed issues and tasklist edges,
+state transitions, title changes and re-parented issues.
+
+# Example
+
+task-graph diff last-week.json graph.json > changes.md
+`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		old, err := read_snapshot(args[0])
+		if err != nil {
+			panic(err)
+		}
+		new, err := read_snapshot(args[1])
+		if err != nil {
+			panic(err)
+		}
+
+		d := taskgraph.Diff(old, new)
+		switch strings.ToLower(diff_format) {
+		case "markdown", "md":
+			err = d.WriteMarkdown(os.Stdout)
+		case "json":
+			err = d.WriteJSON(os.Stdout)
+		case "mermaid":
+			err = d.ToMermaid(os.Stdout, taskgraph.WithDirection(strings.ToUpper(diff_dir)))
+		default:
+			err = fmt.Errorf("bad diff format: %s", diff_format)
+		}
+		if err != nil {
+			panic(err)
+		}
+	},
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"go.resystems.io/task-graph/taskgraph"
+)
+
+var (
+	dot_dir         string = ""
+	dot_skip_closed bool   = false
+)
+
+func init() {
+	rootCmd.AddCommand(dotCmd)
+
+	dotCmd.Flags().StringVarP(&dot_dir, "dir", "d", "", "flow direction: TB, BT, LR or RL (default TB, or that of the --theme)")
+	dotCmd.Flags().BoolVarP(&dot_skip_closed, "skip-closed", "c", false, "skip traversing closed issues")
+	add_snapshot_flag(dotCmd)
+	add_render_flags(dotCmd)
+	add_group_flag(dotCmd)
+}
+
+var dotCmd = &cobra.Command{
+	Use:   "dot",
+	Short: "generate a Graphviz DOT graph of tasks.",
+	Long: `Fetch tasklists embedded in a root issue
+and produce a Graphviz DOT graph thereof.
+
+DOT copes with far larger graphs than Mermaid. Nodes link
+back to their issues when rendered as SVG.
+
+# Example
+
+task-graph -o resystems-io -r architecture -n 8 dot -d LR | dot -Tsvg > tg-8.svg
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		render_graph(dot_skip_closed, (*taskgraph.TaskGraph).ToDot,
+			taskgraph.WithDirection(strings.ToUpper(dot_dir)))
+	},
+}
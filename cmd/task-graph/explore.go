@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"go.resystems.io/task-graph/taskgraph"
+)
+
+var (
+	explore_dir         string = ""
+	explore_skip_closed bool   = false
+)
+
+func init() {
+	rootCmd.AddCommand(exploreCmd)
+
+	exploreCmd.Flags().StringVarP(&explore_dir, "dir", "d", "", "flow direction: TB, BT, LR or RL (default TB, or that of the --theme)")
+	exploreCmd.Flags().BoolVarP(&explore_skip_closed, "skip-closed", "c", false, "skip traversing closed issues")
+	add_snapshot_flag(exploreCmd)
+	add_render_flags(exploreCmd)
+}
+
+var exploreCmd = &cobra.Command{
+	Use:   "explore",
+	Short: "generate an interactive HTML explorer of tasks.",
+	Long: `Fetch tasklists embedded in a root issue
+and produce a single HTML page for exploring them.
+
+The page needs no network access. It can search for tasks,
+filter them by state, repo, label and assignee, collapse
+subtrees, and pan and zoom around large graphs. Hovering
+over a task shows its details and an excerpt of its body,
+and clicking on it opens the issue.
+
+# Example
+
+task-graph -o resystems-io -r architecture -n 8 explore > tg-8.html
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		render_graph(explore_skip_closed, (*taskgraph.TaskGraph).WriteExplorer,
+			taskgraph.WithDirection(strings.ToUpper(explore_dir)))
+	},
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"go.resystems.io/task-graph/taskgraph"
+)
+
+var (
+	gantt_with_html   bool     = false
+	gantt_with_cdn    bool     = false
+	gantt_with_fence  bool     = false
+	gantt_skip_closed bool     = false
+	gantt_start       []string = taskgraph.DefaultStartFields
+	gantt_due         []string = taskgraph.DefaultDueFields
+)
+
+func init() {
+	rootCmd.AddCommand(ganttCmd)
+
+	ganttCmd.Flags().BoolVarP(&gantt_with_html, "browser", "b", false, "encase in HTML for viewing in a browser")
+	ganttCmd.Flags().BoolVar(&gantt_with_cdn, "cdn", false, "with --browser, load Mermaid from the jsdelivr CDN rather than inlining it")
+	ganttCmd.Flags().BoolVarP(&gantt_with_fence, "fence", "f", false, "encase in ```mermaid ... ``` fence")
+	ganttCmd.Flags().BoolVarP(&gantt_skip_closed, "skip-closed", "c", false, "skip traversing closed issues")
+	ganttCmd.Flags().StringSliceVar(&gantt_start, "start-field", taskgraph.DefaultStartFields, "project fields, or front-matter keys, holding the start date of an issue")
+	ganttCmd.Flags().StringSliceVar(&gantt_due, "due-field", taskgraph.DefaultDueFields, "project fields, or front-matter keys, holding the due date of an issue")
+	add_snapshot_flag(ganttCmd)
+	add_render_flags(ganttCmd)
+}
+
+var ganttCmd = &cobra.Command{
+	Use:   "gantt",
+	Short: "generate a mermaid gantt chart of tasks.",
+	Long: `Fetch tasklists embedded in a root issue
+and produce a mermaid gantt chart thereof.
+
+Each issue with a tasklist is an epic, with its own section.
+Issues are scheduled by their start and due dates, taken from
+project fields (see --fields) or the front-matter of the issue
+body, and otherwise by when they were created and closed and
+the due dates of their milestones.
+
+# Example
+
+task-graph -o resystems-io -r architecture -n 8 --fields "Start date,Due date" gantt -b > tg-8.html
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := traversal_context()
+		defer cancel()
+
+		// accumulate linked issues
+		tg, err := load_graph(ctx, taskgraph.WithSkipClosed(gantt_skip_closed))
+		if err != nil {
+			panic(err)
+		}
+
+		opts, err := render_options()
+		if err != nil {
+			panic(err)
+		}
+		opts = append(opts, taskgraph.WithDateFields(gantt_start, gantt_due))
+
+		head, tail, err := mermaid_wrapper(gantt_with_html, gantt_with_fence, gantt_with_cdn)
+		if err != nil {
+			panic(err)
+		}
+		os.Stdout.WriteString(head)
+		defer os.Stdout.WriteString(tail)
+		err = tg.ToGantt(os.Stdout, opts...)
+		if err != nil {
+			panic(err)
+		}
+	},
+}
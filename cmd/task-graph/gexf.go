@@ -0,0 +1,36 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"go.resystems.io/task-graph/taskgraph"
+)
+
+var gexf_skip_closed bool = false
+
+func init() {
+	rootCmd.AddCommand(gexfCmd)
+
+	gexfCmd.Flags().BoolVarP(&gexf_skip_closed, "skip-closed", "c", false, "skip traversing closed issues")
+	add_snapshot_flag(gexfCmd)
+	add_render_flags(gexfCmd)
+}
+
+var gexfCmd = &cobra.Command{
+	Use:   "gexf",
+	Short: "export the graph of tasks as GEXF.",
+	Long: `Fetch tasklists embedded in a root issue
+and export the graph as GEXF, for analysis in Gephi.
+
+Nodes are identified by their issue reference, and carry the
+owner, repo, state, labels, assignees and dates of the issue.
+Edges carry their kind and whether their checkbox is ticked.
+
+# Example
+
+task-graph -o resystems-io -r architecture -n 8 gexf > tg-8.gexf
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		render_graph(gexf_skip_closed, (*taskgraph.TaskGraph).WriteGEXF)
+	},
+}
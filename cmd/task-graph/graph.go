@@ -0,0 +1,337 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/google/go-github/v52/github"
+	"github.com/spf13/cobra"
+	"golang.org/x/oauth2"
+
+	"go.resystems.io/task-graph/taskgraph"
+)
+
+// github_client authenticates to GitHub using the configured access token.
+func github_client(ctx context.Context) (*github.Client, error) {
+	ghtok, err := github_access_token(root_access)
+	if err != nil {
+		return nil, err
+	}
+	ts := oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: ghtok},
+	)
+	tc := oauth2.NewClient(ctx, ts)
+	return github.NewClient(tc), nil
+}
+
+// traversal_context is cancelled on SIGINT or once the root timeout expires.
+//
+// Only the first interrupt is caught, a second interrupt will terminate the
+// process as usual.
+func traversal_context() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	if root_timeout <= 0 {
+		return ctx, stop
+	}
+	ctx, cancel := context.WithTimeout(ctx, root_timeout)
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
+
+// root_issue_refs lists the root issues selected on the command line, either
+// the single --issue reference or one per --issue-number.
+func root_issue_refs() ([]*taskgraph.IssueRef, error) {
+	if root_issue != "" {
+		rootIssue, err := taskgraph.ParseIssueRef(root_issue)
+		if err != nil {
+			return nil, err
+		}
+		if rootIssue.Owner == "" {
+			rootIssue.Owner = root_issue_owner
+			rootIssue.Repo = root_issue_repo
+		}
+		return []*taskgraph.IssueRef{rootIssue}, nil
+	}
+	rootIssues := make([]*taskgraph.IssueRef, len(root_issue_numbers))
+	for i, n := range root_issue_numbers {
+		rootIssue := &taskgraph.IssueRef{Owner: root_issue_owner, Repo: root_issue_repo, Number: n}
+		rootIssues[i] = rootIssue
+	}
+	return rootIssues, nil
+}
+
+// root_logger builds the verbose logger, or returns nil if verbose output was
+// not requested.
+func root_logger() (*slog.Logger, error) {
+	if !root_verbose {
+		return nil, nil
+	}
+	var level slog.Level
+	switch strings.ToLower(root_log_level) {
+	case "info":
+		level = slog.LevelInfo
+	case "debug":
+		level = slog.LevelDebug
+	case "trace":
+		level = taskgraph.LevelTrace
+	default:
+		return nil, fmt.Errorf("bad log level: %s", root_log_level)
+	}
+	opts := &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.LevelKey && a.Value.Any() == taskgraph.LevelTrace {
+				a.Value = slog.StringValue("TRACE")
+			}
+			return a
+		},
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, opts)), nil
+}
+
+// accumulate walks the task graph from the roots selected on the command line.
+//
+// An interrupted or timed out traversal is not fatal, the partial graph is
+// kept and a warning is issued instead.
+func accumulate(ctx context.Context, opts ...taskgraph.Option) (*taskgraph.TaskGraph, error) {
+	roots, err := root_issue_refs()
+	if err != nil {
+		return nil, err
+	}
+	client, err := github_client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	logger, err := root_logger()
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, taskgraph.WithLogger(logger), taskgraph.WithWorkers(root_workers))
+	if len(root_fields) > 0 {
+		opts = append(opts, taskgraph.WithFields(root_fields...))
+	}
+	finish := func() {}
+	if root_progress && is_terminal(os.Stderr) {
+		var update func(taskgraph.Progress)
+		update, finish = progress_bar(os.Stderr)
+		opts = append(opts, taskgraph.WithProgress(update))
+	}
+
+	tg := taskgraph.New(opts...)
+	err = tg.Accumulate(ctx, client, roots...)
+	finish()
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		fmt.Fprintf(os.Stderr, "traversal stopped early (%v): %d issues fetched, %d left incomplete\n",
+			err, len(tg.Refs), len(tg.Incomplete))
+		return tg, nil
+	}
+	return tg, err
+}
+
+var from_snapshot string
+
+// add_snapshot_flag lets a render command read its graph from a snapshot.
+func add_snapshot_flag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&from_snapshot, "from-snapshot", "", "render a snapshot file, written by the snapshot command, instead of fetching from GitHub")
+}
+
+// load_graph reads the graph from the --from-snapshot file if one was given,
+// otherwise the graph is accumulated from GitHub.
+func load_graph(ctx context.Context, opts ...taskgraph.Option) (*taskgraph.TaskGraph, error) {
+	if from_snapshot == "" {
+		return accumulate(ctx, opts...)
+	}
+	return read_snapshot(from_snapshot, opts...)
+}
+
+// read_snapshot reads a graph from a snapshot file.
+func read_snapshot(path string, opts ...taskgraph.Option) (*taskgraph.TaskGraph, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	tg, err := taskgraph.ReadSnapshot(f, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return tg, nil
+}
+
+var (
+	render_status_map  string
+	render_theme_file  string
+	render_node_label  string
+	render_rollup      bool
+	render_weighted    bool
+	render_estimates   []string = taskgraph.DefaultEstimateFields
+	render_hide_closed bool
+	render_group_by    string = taskgraph.GroupRepo
+)
+
+// add_render_flags adds the filtering and styling options shared by the
+// render commands.
+func add_render_flags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&render_status_map, "status-map", "", "JSON file mapping labels, project status and close reasons onto status classes")
+	cmd.Flags().StringVar(&render_theme_file, "theme", "", "JSON file setting the title, direction, palette, dark mode and Mermaid configuration")
+	cmd.Flags().BoolVar(&render_hide_closed, "hide-closed", false, "leave closed issues out of the output")
+	cmd.Flags().BoolVar(&render_rollup, "rollup", false, "show how many of the descendants of each issue are closed")
+	add_estimate_flags(cmd)
+	cmd.Flags().StringVar(&render_node_label, "node-label", "", "Go text/template for the node labels, e.g. '{{.Ref}} {{.Title}}\\n{{join \", \" .Assignees}}'")
+}
+
+// add_estimate_flags adds the options that weigh issues by their estimates.
+func add_estimate_flags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&render_weighted, "weighted", false, "weigh issues by their estimates, rather than counting them")
+	cmd.Flags().StringSliceVar(&render_estimates, "estimate-field", taskgraph.DefaultEstimateFields, "project fields, or front-matter keys, holding the estimate of an issue")
+}
+
+// render_estimate_fields lists the fields holding the estimates of issues, or
+// nil if issues are counted rather than weighed.
+func render_estimate_fields() []string {
+	if !render_weighted {
+		return nil
+	}
+	return render_estimates
+}
+
+// render_theme reads the --theme file, or returns the default theme.
+func render_theme() (*taskgraph.Theme, error) {
+	if render_theme_file == "" {
+		return taskgraph.DefaultTheme(), nil
+	}
+	f, err := os.Open(render_theme_file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	theme, err := taskgraph.ReadTheme(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", render_theme_file, err)
+	}
+	return theme, nil
+}
+
+// add_group_flag adds the grouping option of the renderers that draw groups
+// of issues.
+func add_group_flag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&render_group_by, "group-by", taskgraph.GroupRepo,
+		"group issues by repo, owner, owner/repo, milestone, assignee, label:<prefix>, field:<name> or none")
+}
+
+// render_options collects the shared filtering and styling options.
+func render_options() ([]taskgraph.RenderOption, error) {
+	var opts []taskgraph.RenderOption
+	grouping, err := taskgraph.ParseGrouping(render_group_by)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, taskgraph.WithGrouping(grouping))
+	theme, err := render_theme()
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, taskgraph.WithTheme(theme))
+	if render_node_label != "" {
+		label, err := taskgraph.ParseNodeLabel(render_node_label)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, taskgraph.WithNodeLabel(label))
+	}
+	if render_hide_closed {
+		opts = append(opts, taskgraph.WithFilter(taskgraph.HideClosed))
+	}
+	opts = append(opts, taskgraph.WithRollup(render_rollup))
+	opts = append(opts, taskgraph.WithEstimates(render_estimate_fields()))
+	if render_status_map != "" {
+		f, err := os.Open(render_status_map)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		sm, err := taskgraph.ReadStatusMap(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", render_status_map, err)
+		}
+		opts = append(opts, taskgraph.WithStatusMap(sm))
+	}
+	return opts, nil
+}
+
+// render_graph loads the graph and writes it to stdout using one of the
+// renderers, e.g. (*taskgraph.TaskGraph).ToDot, with the shared render
+// options followed by any given options.
+func render_graph(skip_closed bool, render func(*taskgraph.TaskGraph, io.Writer, ...taskgraph.RenderOption) error, extra ...taskgraph.RenderOption) {
+	render_graph_to("", skip_closed, render, extra...)
+}
+
+// render_graph_to is render_graph, but writes to the output file if one is
+// given. The file is only written once the graph has been rendered in full.
+func render_graph_to(output string, skip_closed bool, render func(*taskgraph.TaskGraph, io.Writer, ...taskgraph.RenderOption) error, extra ...taskgraph.RenderOption) {
+	ctx, cancel := traversal_context()
+	defer cancel()
+
+	// accumulate linked issues
+	tg, err := load_graph(ctx, taskgraph.WithSkipClosed(skip_closed))
+	if err != nil {
+		panic(err)
+	}
+
+	opts, err := render_options()
+	if err != nil {
+		panic(err)
+	}
+	err = write_output(output, func(w io.Writer) error {
+		return render(tg, w, append(opts, extra...)...)
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// write_output writes to the output file, or to stdout if none is given. The
+// file is only written once write has succeeded.
+func write_output(output string, write func(io.Writer) error) error {
+	if output == "" {
+		return write(os.Stdout)
+	}
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		return err
+	}
+	return os.WriteFile(output, buf.Bytes(), 0o644)
+}
+
+var (
+	page_size string
+	page_tile bool
+)
+
+// add_page_flags adds the page layout options of the document renderers.
+func add_page_flags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&page_size, "page", "fit", "page size: fit, a3, a4, letter or legal, optionally followed by -landscape")
+	cmd.Flags().BoolVar(&page_tile, "tile", false, "spread large graphs across several pages instead of scaling them to fit")
+}
+
+// page_options collects the page layout options.
+func page_options() ([]taskgraph.RenderOption, error) {
+	page, err := taskgraph.ParsePageSize(page_size)
+	if err != nil {
+		return nil, err
+	}
+	return []taskgraph.RenderOption{taskgraph.WithPageSize(page), taskgraph.WithTiling(page_tile)}, nil
+}
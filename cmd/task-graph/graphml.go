@@ -0,0 +1,36 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"go.resystems.io/task-graph/taskgraph"
+)
+
+var graphml_skip_closed bool = false
+
+func init() {
+	rootCmd.AddCommand(graphmlCmd)
+
+	graphmlCmd.Flags().BoolVarP(&graphml_skip_closed, "skip-closed", "c", false, "skip traversing closed issues")
+	add_snapshot_flag(graphmlCmd)
+	add_render_flags(graphmlCmd)
+}
+
+var graphmlCmd = &cobra.Command{
+	Use:   "graphml",
+	Short: "export the graph of tasks as GraphML.",
+	Long: `Fetch tasklists embedded in a root issue
+and export the graph as GraphML, for analysis in yEd.
+
+Nodes are identified by their issue reference, and carry the
+owner, repo, state, labels, assignees and dates of the issue.
+Edges carry their kind and whether their checkbox is ticked.
+
+# Example
+
+task-graph -o resystems-io -r architecture -n 8 graphml > tg-8.graphml
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		render_graph(graphml_skip_closed, (*taskgraph.TaskGraph).WriteGraphML)
+	},
+}
@@ -8,14 +8,14 @@ import (
 	"github.com/google/go-github/v52/github"
 	"github.com/spf13/cobra"
 	"golang.org/x/oauth2"
-
-	"go.resystems.io/task-graph/internal/taskgraph"
 )
 
 func init() {
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(listPersonalReposCmd)
 	rootCmd.AddCommand(listPublicReposCmd)
+
+	add_snapshot_flag(listCmd)
 }
 
 var listCmd = &cobra.Command{
@@ -25,32 +25,11 @@ var listCmd = &cobra.Command{
 the graph of issue from there.
 `,
 	Run: func(cmd *cobra.Command, args []string) {
-		if root_issue != "" {
-			panic("issue tags not yet supported")
-		}
-
-		// authenticate to github
-		ghtok, err := github_access_token(root_access)
-		if err != nil {
-			panic(err)
-		}
-		ts := oauth2.StaticTokenSource(
-			&oauth2.Token{AccessToken: ghtok},
-		)
-		ctx := context.Background()
-		tc := oauth2.NewClient(ctx, ts)
-		client := github.NewClient(tc)
-
-		// check root numbers
-		if len(root_issue_numbers) == 0 {
-			return
-		}
+		ctx, cancel := traversal_context()
+		defer cancel()
 
 		// accumulate linked issues
-		rootIssue := &taskgraph.IssueRef{Owner:root_issue_owner, Repo:root_issue_repo, Number:root_issue_numbers[0]}
-		tg := taskgraph.TaskGraph{}
-		tg.Verbose(root_verbose)
-		err = tg.Accumulate(ctx, client, rootIssue)
+		tg, err := load_graph(ctx)
 		if err != nil {
 			panic(err)
 		}
@@ -58,7 +37,10 @@ the graph of issue from there.
 		fmt.Fprintf(os.Stdout, "edges: %v\n", tg.Edges)
 
 		for k, h := range tg.Refs {
-			fmt.Fprintf(os.Stdout, "%s %s\n", k, *h.Issue.Title)
+			fmt.Fprintf(os.Stdout, "%s %s\n", k, h.Title)
+		}
+		for k := range tg.Incomplete {
+			fmt.Fprintf(os.Stdout, "%s (incomplete)\n", k)
 		}
 	},
 }
@@ -5,10 +5,13 @@ import (
 	"os"
 	"path"
 	"strings"
+	"time"
 
 	_ "github.com/google/go-github/v52/github"
 	"github.com/spf13/cobra"
 	_ "github.com/yuin/goldmark"
+
+	"go.resystems.io/task-graph/taskgraph"
 )
 
 var (
@@ -18,15 +21,25 @@ var (
 	root_issue_owner   string
 	root_issue_repo    string
 	root_issue_numbers []int
+	root_workers       int
+	root_timeout       time.Duration
+	root_log_level     string
+	root_progress      bool
+	root_fields        []string
 )
 
 func init() {
 	rootCmd.Flags().StringVarP(&root_access, "access-token", "a", "~/.config/task-graph/github_access_token", "file from which to load the GitHub access token.")
 	rootCmd.Flags().BoolVarP(&root_verbose, "verbose", "v", false, "verbose output to stderr")
+	rootCmd.Flags().StringVar(&root_log_level, "log-level", "info", "verbose output level: info, debug or trace")
+	rootCmd.Flags().BoolVarP(&root_progress, "progress", "p", false, "show a progress bar on stderr while traversing (terminals only)")
+	rootCmd.Flags().StringSliceVar(&root_fields, "fields", nil, "also fetch these GitHub project fields, e.g. Status (costs a request per issue)")
 	rootCmd.Flags().StringVarP(&root_issue, "issue", "i", "", "root issue owner/repo#123")
 	rootCmd.Flags().StringVarP(&root_issue_owner, "issue-owner", "o", "", "root issue owner")
 	rootCmd.Flags().StringVarP(&root_issue_repo, "issue-repo", "r", "", "root issue repo")
 	rootCmd.Flags().IntSliceVarP(&root_issue_numbers, "issue-number", "n", []int{1}, "root issue number (repeat for multiple roots)")
+	rootCmd.Flags().IntVarP(&root_workers, "workers", "w", taskgraph.DefaultWorkers, "number of issues to fetch concurrently")
+	rootCmd.Flags().DurationVarP(&root_timeout, "timeout", "t", 0, "stop traversing after this long and render what was fetched (0 for no limit)")
 }
 
 func main() {
@@ -1,32 +1,37 @@
 package main
 
 import (
-	"context"
 	_ "embed"
+	"html/template"
 	"os"
 	"strings"
 
-	"github.com/google/go-github/v52/github"
 	"github.com/spf13/cobra"
-	"golang.org/x/oauth2"
 
-	"go.resystems.io/task-graph/internal/taskgraph"
+	"go.resystems.io/task-graph/taskgraph"
 )
 
 var (
 	mermaid_with_html   bool   = false
+	mermaid_with_cdn    bool   = false
 	mermaid_with_fence  bool   = false
-	mermaid_dir         string = "TB"
+	mermaid_dir         string = ""
 	mermaid_skip_closed bool   = false
+	mermaid_critical    bool   = false
 )
 
 func init() {
 	rootCmd.AddCommand(listMermaidCmd)
 
 	listMermaidCmd.Flags().BoolVarP(&mermaid_with_html, "browser", "b", false, "encase in HTML for viewing in a browser")
+	listMermaidCmd.Flags().BoolVar(&mermaid_with_cdn, "cdn", false, "with --browser, load Mermaid from the jsdelivr CDN rather than inlining it")
 	listMermaidCmd.Flags().BoolVarP(&mermaid_with_fence, "fence", "f", false, "encase in ```mermaid ... ``` fence")
-	listMermaidCmd.Flags().StringVarP(&mermaid_dir, "dir", "d", "TB", "use TB or LR flow direction")
+	listMermaidCmd.Flags().StringVarP(&mermaid_dir, "dir", "d", "", "flow direction: TB, BT, LR or RL (default TB, or that of the --theme)")
 	listMermaidCmd.Flags().BoolVarP(&mermaid_skip_closed, "skip-closed", "c", false, "skip traversing closed issues")
+	listMermaidCmd.Flags().BoolVar(&mermaid_critical, "critical-path", false, "highlight the longest chain of open issues")
+	add_snapshot_flag(listMermaidCmd)
+	add_render_flags(listMermaidCmd)
+	add_group_flag(listMermaidCmd)
 }
 
 //go:embed mermaid.head.html
@@ -35,62 +40,97 @@ var mermaid_head_html string
 //go:embed mermaid.tail.html
 var mermaid_tail_html string
 
+//go:embed mermaid.tail.offline.html
+var mermaid_tail_offline_html string
+
+// mermaid_runtime is mermaid.min.js, see mermaid_runtime/README.md.
+//
+//go:embed mermaid_runtime/mermaid.min.js
+var mermaid_runtime string
+
+// mermaid_offline_tail inlines the embedded Mermaid runtime, so that the HTML
+// can be viewed without network access.
+func mermaid_offline_tail() string {
+	// the runtime must not close its own script element
+	runtime := strings.ReplaceAll(mermaid_runtime, "</script", "<\\/script")
+	return strings.Replace(mermaid_tail_offline_html, "<!-- mermaid runtime -->", runtime, 1)
+}
+
 const (
 	mermaid_head_fence = "```mermaid\n"
 	mermaid_tail_fence = "\n```\n"
 )
 
+// mermaid_head is the head of the HTML page, titled and coloured by the
+// theme.
+func mermaid_head() (string, error) {
+	theme, err := render_theme()
+	if err != nil {
+		return "", err
+	}
+	t, err := template.New("head").Parse(mermaid_head_html)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	err = t.Execute(&b, theme)
+	return b.String(), err
+}
+
+// mermaid_wrapper returns what to write before and after a Mermaid diagram,
+// to encase it in HTML or a Markdown fence.
+func mermaid_wrapper(html, fence, cdn bool) (head, tail string, err error) {
+	switch {
+	case html && cdn:
+		head, err = mermaid_head()
+		return head, mermaid_tail_html, err
+	case html:
+		head, err = mermaid_head()
+		return head, mermaid_offline_tail(), err
+	case fence:
+		return mermaid_head_fence, mermaid_tail_fence, nil
+	}
+	return "", "", nil
+}
+
 var listMermaidCmd = &cobra.Command{
 	Use:   "mermaid",
 	Short: "generate a mermaid graph of tasks.",
 	Long: `Fetch tasklists embedded in a root issue
 and produce a mermaid graph thereof.
 
+With --browser the Mermaid runtime is inlined, so that the
+HTML can be viewed offline. Use --cdn for a smaller file
+that loads Mermaid from the jsdelivr CDN instead.
+
 # Example
 
 task-graph -o resystems-io -r architecture -n 2 -v mermaid -d LR -b -c > tg-2.html
 `,
 	Run: func(cmd *cobra.Command, args []string) {
-		if root_issue != "" {
-			panic("issue tags not yet supported")
-		}
+		ctx, cancel := traversal_context()
+		defer cancel()
 
-		// authenticate to github
-		ghtok, err := github_access_token(root_access)
+		// accumulate linked issues
+		tg, err := load_graph(ctx, taskgraph.WithSkipClosed(mermaid_skip_closed))
 		if err != nil {
 			panic(err)
 		}
-		ts := oauth2.StaticTokenSource(
-			&oauth2.Token{AccessToken: ghtok},
-		)
-		ctx := context.Background()
-		tc := oauth2.NewClient(ctx, ts)
-		client := github.NewClient(tc)
 
-		// accumulate linked issues
-		tg := taskgraph.TaskGraph{}
-		tg.Verbose(root_verbose)
-		tg.SkipClosed(mermaid_skip_closed)
-
-		rootIssues := make([]*taskgraph.IssueRef, len(root_issue_numbers))
-		for i, n := range root_issue_numbers {
-			rootIssue := &taskgraph.IssueRef{Owner: root_issue_owner, Repo: root_issue_repo, Number: n}
-			rootIssues[i] = rootIssue
-		}
-		err = tg.Accumulate(ctx, client, rootIssues...)
+		opts, err := render_options()
 		if err != nil {
 			panic(err)
 		}
+		opts = append(opts, taskgraph.WithDirection(strings.ToUpper(mermaid_dir)))
+		opts = append(opts, taskgraph.WithCriticalPath(mermaid_critical))
 
-		if mermaid_with_html {
-			os.Stdout.WriteString(mermaid_head_html)
-			defer os.Stdout.WriteString(mermaid_tail_html)
-		} else if mermaid_with_fence {
-			os.Stdout.WriteString(mermaid_head_fence)
-			defer os.Stdout.WriteString(mermaid_tail_fence)
+		head, tail, err := mermaid_wrapper(mermaid_with_html, mermaid_with_fence, mermaid_with_cdn)
+		if err != nil {
+			panic(err)
 		}
-		mermaid_dir = strings.ToUpper(mermaid_dir)
-		err = tg.ToMermaid(os.Stdout, mermaid_dir)
+		os.Stdout.WriteString(head)
+		defer os.Stdout.WriteString(tail)
+		err = tg.ToMermaid(os.Stdout, opts...)
 		if err != nil {
 			panic(err)
 		}
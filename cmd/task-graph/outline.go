@@ -0,0 +1,37 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"go.resystems.io/task-graph/taskgraph"
+)
+
+var (
+	outline_skip_closed bool = false
+)
+
+func init() {
+	rootCmd.AddCommand(outlineCmd)
+
+	outlineCmd.Flags().BoolVarP(&outline_skip_closed, "skip-closed", "c", false, "skip traversing closed issues")
+	add_snapshot_flag(outlineCmd)
+	add_render_flags(outlineCmd)
+}
+
+var outlineCmd = &cobra.Command{
+	Use:   "outline",
+	Short: "generate a markdown outline of tasks.",
+	Long: `Fetch tasklists embedded in a root issue
+and produce nested markdown checklists thereof.
+
+Closed issues are ticked. An issue listed by several parents
+is shown in full once, and elsewhere marked "(see above)".
+
+# Example
+
+task-graph -o resystems-io -r architecture -n 8 outline > tg-8.md
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		render_graph(outline_skip_closed, (*taskgraph.TaskGraph).ToOutline)
+	},
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"go.resystems.io/task-graph/taskgraph"
+)
+
+var (
+	pdf_output      string = ""
+	pdf_dir         string = ""
+	pdf_skip_closed bool   = false
+)
+
+func init() {
+	rootCmd.AddCommand(pdfCmd)
+
+	pdfCmd.Flags().StringVarP(&pdf_output, "output", "o", "", "write to this file instead of stdout")
+	pdfCmd.Flags().StringVarP(&pdf_dir, "dir", "d", "", "flow direction: TB, BT, LR or RL (default TB, or that of the --theme)")
+	pdfCmd.Flags().BoolVarP(&pdf_skip_closed, "skip-closed", "c", false, "skip traversing closed issues")
+	add_page_flags(pdfCmd)
+	add_snapshot_flag(pdfCmd)
+	add_render_flags(pdfCmd)
+}
+
+var pdfCmd = &cobra.Command{
+	Use:   "pdf",
+	Short: "generate a PDF document of tasks.",
+	Long: `Fetch tasklists embedded in a root issue
+and draw them as a vector PDF document, e.g. for reports.
+
+The graph is laid out and drawn by task-graph itself, so no
+browser is needed. Large graphs are scaled down to fit the
+page, or with --tile spread across several pages at full
+size. Nodes link back to their issues.
+
+# Example
+
+task-graph -o resystems-io -r architecture -n 8 pdf --page a4-landscape -o tg-8.pdf
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		opts, err := page_options()
+		if err != nil {
+			panic(err)
+		}
+		opts = append(opts, taskgraph.WithDirection(strings.ToUpper(pdf_dir)))
+		render_graph_to(pdf_output, pdf_skip_closed, (*taskgraph.TaskGraph).WritePDF, opts...)
+	},
+}
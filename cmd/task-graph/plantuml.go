@@ -0,0 +1,40 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"go.resystems.io/task-graph/taskgraph"
+)
+
+var (
+	plantuml_dir         string = ""
+	plantuml_skip_closed bool   = false
+)
+
+func init() {
+	rootCmd.AddCommand(plantumlCmd)
+
+	plantumlCmd.Flags().StringVarP(&plantuml_dir, "dir", "d", "", "flow direction: TB, BT, LR or RL (default TB, or that of the --theme)")
+	plantumlCmd.Flags().BoolVarP(&plantuml_skip_closed, "skip-closed", "c", false, "skip traversing closed issues")
+	add_snapshot_flag(plantumlCmd)
+	add_render_flags(plantumlCmd)
+	add_group_flag(plantumlCmd)
+}
+
+var plantumlCmd = &cobra.Command{
+	Use:   "plantuml",
+	Short: "generate a PlantUML diagram of tasks.",
+	Long: `Fetch tasklists embedded in a root issue
+and produce a PlantUML diagram thereof.
+
+# Example
+
+task-graph -o resystems-io -r architecture -n 8 plantuml -d LR > tg-8.puml
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		render_graph(plantuml_skip_closed, (*taskgraph.TaskGraph).ToPlantUML,
+			taskgraph.WithDirection(strings.ToUpper(plantuml_dir)))
+	},
+}
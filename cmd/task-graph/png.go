@@ -0,0 +1,52 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"go.resystems.io/task-graph/taskgraph"
+)
+
+var (
+	png_output      string  = ""
+	png_dir         string  = ""
+	png_skip_closed bool    = false
+	png_dpi         float64 = taskgraph.DefaultDPI
+)
+
+func init() {
+	rootCmd.AddCommand(pngCmd)
+
+	pngCmd.Flags().StringVarP(&png_output, "output", "o", "", "write to this file instead of stdout")
+	pngCmd.Flags().StringVarP(&png_dir, "dir", "d", "", "flow direction: TB, BT, LR or RL (default TB, or that of the --theme)")
+	pngCmd.Flags().BoolVarP(&png_skip_closed, "skip-closed", "c", false, "skip traversing closed issues")
+	pngCmd.Flags().Float64Var(&png_dpi, "dpi", taskgraph.DefaultDPI, "resolution of the image")
+	add_page_flags(pngCmd)
+	add_snapshot_flag(pngCmd)
+	add_render_flags(pngCmd)
+}
+
+var pngCmd = &cobra.Command{
+	Use:   "png",
+	Short: "generate a PNG image of tasks.",
+	Long: `Fetch tasklists embedded in a root issue
+and draw them as a PNG image, e.g. for slide decks.
+
+The graph is laid out and drawn by task-graph itself, so no
+browser is needed. The graph can be drawn at a given size
+of page, in which case it is scaled down to fit.
+
+# Example
+
+task-graph -o resystems-io -r architecture -n 8 png --dpi 150 -o tg-8.png
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		opts, err := page_options()
+		if err != nil {
+			panic(err)
+		}
+		opts = append(opts, taskgraph.WithDirection(strings.ToUpper(png_dir)), taskgraph.WithDPI(png_dpi))
+		render_graph_to(png_output, png_skip_closed, (*taskgraph.TaskGraph).WritePNG, opts...)
+	},
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go.resystems.io/task-graph/taskgraph"
+)
+
+const progress_bar_width = 30
+
+// is_terminal reports whether the file is attached to a character device.
+func is_terminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// progress_bar draws traversal progress on a single, continually rewritten,
+// line of the given terminal. The returned finish function ends the line.
+func progress_bar(f *os.File) (update func(taskgraph.Progress), finish func()) {
+	started := time.Now()
+	drawn := false
+	update = func(p taskgraph.Progress) {
+		total := p.Fetched + p.Pending
+		filled := 0
+		if total > 0 {
+			filled = progress_bar_width * p.Fetched / total
+		}
+		bar := strings.Repeat("#", filled) + strings.Repeat("-", progress_bar_width-filled)
+		rate := ""
+		if p.RateRemaining >= 0 {
+			rate = fmt.Sprintf(", rate-limit remaining %d", p.RateRemaining)
+		}
+		fmt.Fprintf(f, "\r\033[K[%s] fetched %d, pending %d, failed %d%s (%s)",
+			bar, p.Fetched, p.Pending, p.Failed, rate, time.Since(started).Round(time.Second))
+		drawn = true
+	}
+	finish = func() {
+		if drawn {
+			fmt.Fprintln(f)
+		}
+	}
+	return update, finish
+}
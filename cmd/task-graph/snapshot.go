@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"go.resystems.io/task-graph/taskgraph"
+)
+
+var (
+	snapshot_output      string = ""
+	snapshot_skip_closed bool   = false
+	snapshot_schema      bool   = false
+)
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+
+	snapshotCmd.Flags().StringVarP(&snapshot_output, "output", "o", "", "write the snapshot to this file instead of stdout")
+	snapshotCmd.Flags().BoolVarP(&snapshot_skip_closed, "skip-closed", "c", false, "skip traversing closed issues")
+	snapshotCmd.Flags().BoolVar(&snapshot_schema, "schema", false, "print the JSON Schema of the snapshot format and exit")
+}
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "save the task graph as a JSON snapshot.",
+	Long: `Fetch tasklists embedded in a root issue and save
+the resulting graph as a versioned JSON snapshot.
+
+Snapshots can be rendered offline, by passing --from-snapshot
+to any of the render commands.
+
+# Example
+
+task-graph -o resystems-io -r architecture -n 8 snapshot -o graph.json
+task-graph mermaid --from-snapshot graph.json -b > tg-8.html
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if snapshot_schema {
+			os.Stdout.WriteString(taskgraph.SnapshotSchema)
+			return
+		}
+
+		ctx, cancel := traversal_context()
+		defer cancel()
+
+		// accumulate linked issues
+		tg, err := accumulate(ctx, taskgraph.WithSkipClosed(snapshot_skip_closed))
+		if err != nil {
+			panic(err)
+		}
+
+		out := os.Stdout
+		if snapshot_output != "" {
+			out, err = os.Create(snapshot_output)
+			if err != nil {
+				panic(err)
+			}
+			defer out.Close()
+		}
+		err = tg.WriteSnapshot(out)
+		if err != nil {
+			panic(err)
+		}
+	},
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"go.resystems.io/task-graph/taskgraph"
+)
+
+var (
+	svg_dir         string = ""
+	svg_skip_closed bool   = false
+)
+
+func init() {
+	rootCmd.AddCommand(svgCmd)
+
+	svgCmd.Flags().StringVarP(&svg_dir, "dir", "d", "", "flow direction: TB, BT, LR or RL (default TB, or that of the --theme)")
+	svgCmd.Flags().BoolVarP(&svg_skip_closed, "skip-closed", "c", false, "skip traversing closed issues")
+	add_snapshot_flag(svgCmd)
+	add_render_flags(svgCmd)
+}
+
+var svgCmd = &cobra.Command{
+	Use:   "svg",
+	Short: "generate an SVG image of tasks.",
+	Long: `Fetch tasklists embedded in a root issue
+and draw them as a static SVG image.
+
+The graph is laid out by task-graph itself, so neither a
+browser nor any other tool is needed. Nodes link back to
+their issues.
+
+# Example
+
+task-graph -o resystems-io -r architecture -n 8 svg > tg-8.svg
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		render_graph(svg_skip_closed, (*taskgraph.TaskGraph).ToSVG,
+			taskgraph.WithDirection(strings.ToUpper(svg_dir)))
+	},
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"go.resystems.io/task-graph/taskgraph"
+)
+
+var (
+	tree_skip_closed     bool   = false
+	tree_depth           int    = 0
+	tree_collapse_closed bool   = false
+	tree_ascii           bool   = false
+	tree_colour          string = "auto"
+	tree_links           string = "auto"
+)
+
+func init() {
+	rootCmd.AddCommand(treeCmd)
+
+	treeCmd.Flags().BoolVarP(&tree_skip_closed, "skip-closed", "c", false, "skip traversing closed issues")
+	treeCmd.Flags().IntVarP(&tree_depth, "depth", "L", 0, "only show this many levels below the roots (0 for all)")
+	treeCmd.Flags().BoolVar(&tree_collapse_closed, "collapse-closed", false, "do not expand the subtasks of closed issues")
+	treeCmd.Flags().BoolVar(&tree_ascii, "ascii", false, "draw with plain ASCII rather than Unicode")
+	treeCmd.Flags().StringVar(&tree_colour, "colour", "auto", "colour by status: auto, always or never")
+	treeCmd.Flags().StringVar(&tree_links, "links", "auto", "link issues with OSC 8 hyperlinks: auto, always or never")
+	add_snapshot_flag(treeCmd)
+	add_render_flags(treeCmd)
+}
+
+// supports_hyperlinks guesses, from the environment, whether the terminal
+// shows OSC 8 hyperlinks. Unsupporting terminals mostly ignore them, but some
+// print them as junk, so only known terminals get them.
+func supports_hyperlinks() bool {
+	switch os.Getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "Hyper", "ghostty":
+		return true
+	}
+	for _, v := range []string{"WT_SESSION", "KITTY_WINDOW_ID", "KONSOLE_VERSION", "DOMTERM"} {
+		if os.Getenv(v) != "" {
+			return true
+		}
+	}
+	if v, err := strconv.Atoi(os.Getenv("VTE_VERSION")); err == nil && v >= 5000 {
+		return true
+	}
+	term := os.Getenv("TERM")
+	return strings.Contains(term, "kitty") || strings.Contains(term, "alacritty") || strings.Contains(term, "foot")
+}
+
+// auto_flag decides an auto, always or never flag.
+func auto_flag(flag, value string, auto func() bool) (bool, error) {
+	switch value {
+	case "auto":
+		return auto(), nil
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	}
+	return false, fmt.Errorf("bad --%s: %q (want auto, always or never)", flag, value)
+}
+
+var treeCmd = &cobra.Command{
+	Use:   "tree",
+	Short: "print a tree of tasks in the terminal.",
+	Long: `Fetch tasklists embedded in a root issue
+and print them as an indented tree, in the style of tree or
+cargo tree.
+
+Issues are coloured by status, and show how many of their
+subtasks are done. An issue listed by several parents is
+expanded under the first, and elsewhere marked (*).
+
+Colour follows NO_COLOR, and hyperlinks are only used on
+terminals known to support them, unless forced.
+
+# Example
+
+task-graph -o resystems-io -r architecture -n 8 tree -L 2 --collapse-closed
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		tty := is_terminal(os.Stdout)
+		colour, err := auto_flag("colour", tree_colour, func() bool { return tty && os.Getenv("NO_COLOR") == "" })
+		if err != nil {
+			panic(err)
+		}
+		links, err := auto_flag("links", tree_links, func() bool { return tty && supports_hyperlinks() })
+		if err != nil {
+			panic(err)
+		}
+		render_graph(tree_skip_closed, (*taskgraph.TaskGraph).ToTree,
+			taskgraph.WithDepth(tree_depth),
+			taskgraph.WithCollapseClosed(tree_collapse_closed),
+			taskgraph.WithASCII(tree_ascii),
+			taskgraph.WithColour(colour),
+			taskgraph.WithHyperlinks(links))
+	},
+}
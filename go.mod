@@ -1,13 +1,13 @@
 module go.resystems.io/task-graph
 
-go 1.20
+go 1.21
 
 require (
 	github.com/google/go-github/v52 v52.0.0
 	github.com/spf13/cobra v1.7.0
 	github.com/yuin/goldmark v1.5.4
+	golang.org/x/image v0.24.0
 	golang.org/x/oauth2 v0.8.0
-	golang.org/x/sync v0.2.0
 )
 
 require (
@@ -20,6 +20,7 @@ require (
 	golang.org/x/crypto v0.9.0 // indirect
 	golang.org/x/net v0.10.0 // indirect
 	golang.org/x/sys v0.8.0 // indirect
+	golang.org/x/text v0.22.0 // indirect
 	google.golang.org/appengine v1.6.7 // indirect
 	google.golang.org/protobuf v1.30.0 // indirect
 )
@@ -0,0 +1,322 @@
+package taskgraph
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v52/github"
+)
+
+// fakeSource serves issues from memory, optionally after an artificial delay.
+type fakeSource struct {
+	issues  map[string]*github.Issue
+	latency func(ref string) time.Duration
+
+	mu      sync.Mutex
+	fetches map[string]int
+}
+
+func newFakeSource() *fakeSource {
+	return &fakeSource{
+		issues:  make(map[string]*github.Issue),
+		fetches: make(map[string]int),
+	}
+}
+
+// add registers an issue in owner/repo with a tasklist referring to the children.
+func (fs *fakeSource) add(owner, repo string, number int, title string, state string, children ...string) {
+	body := ""
+	if len(children) > 0 {
+		var sb strings.Builder
+		sb.WriteString("```[tasklist]\n")
+		for _, c := range children {
+			fmt.Fprintf(&sb, "- [ ] %s\n", c)
+		}
+		sb.WriteString("```\n")
+		body = sb.String()
+	}
+	ref := IssueRef{owner, repo, number}
+	fs.issues[ref.String()] = &github.Issue{
+		Number: github.Int(number),
+		Title:  github.String(title),
+		State:  github.String(state),
+		Body:   github.String(body),
+	}
+}
+
+func (fs *fakeSource) Get(ctx context.Context, owner string, repo string, number int) (*github.Issue, *github.Response, error) {
+	ref := IssueRef{owner, repo, number}
+	nm := ref.String()
+
+	fs.mu.Lock()
+	fs.fetches[nm]++
+	fs.mu.Unlock()
+
+	if fs.latency != nil {
+		select {
+		case <-time.After(fs.latency(nm)):
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+
+	issue, ok := fs.issues[nm]
+	if !ok {
+		return nil, nil, fmt.Errorf("no such issue %v", nm)
+	}
+	return issue, nil, nil
+}
+
+// diamond builds a source where two parents share a child, which in turn has
+// a child of its own.
+func diamond() *fakeSource {
+	fs := newFakeSource()
+	fs.add("o", "r", 1, "root", github_open, "#2", "#3", "o/s#4")
+	fs.add("o", "r", 2, "left", github_open, "#5")
+	fs.add("o", "r", 3, "right", github_open, "#5", "https://github.com/o/s/issues/4")
+	fs.add("o", "s", 4, "other", github_closed)
+	fs.add("o", "r", 5, "shared", github_open, "#6")
+	fs.add("o", "r", 6, "leaf", github_open)
+	return fs
+}
+
+func TestAccumulateFetchesEachIssueOnce(t *testing.T) {
+	fs := diamond()
+	tg := New(WithWorkers(4))
+	err := tg.AccumulateFrom(context.Background(), fs, &IssueRef{"o", "r", 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tg.Refs) != 6 {
+		t.Errorf("expected 6 nodes, got %d", len(tg.Refs))
+	}
+	for nm, n := range fs.fetches {
+		if n != 1 {
+			t.Errorf("%v fetched %d times", nm, n)
+		}
+	}
+
+	expect := map[string][]string{
+		"o/r#1": {"o/r#2", "o/r#3", "o/s#4"},
+		"o/r#2": {"o/r#5"},
+		"o/r#3": {"o/r#5", "o/s#4"},
+		"o/s#4": {},
+		"o/r#5": {"o/r#6"},
+		"o/r#6": {},
+	}
+	for src, dsts := range expect {
+		got := tg.Edges[src]
+		if strings.Join(got, ",") != strings.Join(dsts, ",") {
+			t.Errorf("edges from %v: expected %v, got %v", src, dsts, got)
+		}
+	}
+}
+
+func TestAccumulateBlockers(t *testing.T) {
+	fs := newFakeSource()
+	fs.add("o", "r", 1, "Release", "open", "#2", "#3")
+	fs.add("o", "r", 2, "Design", "open")
+	fs.add("o", "r", 3, "Build", "open")
+	fs.add("o", "s", 9, "Review", "open")
+	fs.issues["o/r#3"].Body = github.String("---\nblocked_by: \"#2, o/s#9\"\n---\n")
+
+	tg := New()
+	if err := tg.AccumulateFrom(context.Background(), fs, &IssueRef{"o", "r", 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := tg.Refs["o/s#9"]; !ok {
+		t.Errorf("expected the blocker to be fetched")
+	}
+	for _, from := range []string{"o/r#2", "o/s#9"} {
+		if e := tg.Edge(from, "o/r#3"); !tg.HasEdge(from, "o/r#3") || e.Kind != EdgeBlocks {
+			t.Errorf("expected %s to block o/r#3, got %+v", from, e)
+		}
+	}
+	if got := tg.tasklist("o/r#1"); strings.Join(got, ",") != "o/r#2,o/r#3" {
+		t.Errorf("expected the tasklist to be unchanged, got %v", got)
+	}
+}
+
+func TestAccumulateDoesNotWaitForSlowSiblings(t *testing.T) {
+	fs := diamond()
+	// the right branch is slow, but the left branch should still be explored
+	fs.latency = func(ref string) time.Duration {
+		if ref == "o/r#3" {
+			return 200 * time.Millisecond
+		}
+		return 0
+	}
+
+	tg := New(WithWorkers(2))
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err := tg.AccumulateFrom(ctx, fs, &IssueRef{"o", "r", 1})
+	if err == nil {
+		t.Fatal("expected a timeout")
+	}
+
+	for _, nm := range []string{"o/r#2", "o/r#5", "o/r#6"} {
+		if _, ok := tg.Refs[nm]; !ok {
+			t.Errorf("expected %v to be fetched while o/r#3 was pending", nm)
+		}
+	}
+	if _, ok := tg.Incomplete["o/r#3"]; !ok {
+		t.Errorf("expected o/r#3 to be marked as incomplete, got %v", tg.Incomplete)
+	}
+
+	// render the partial graph
+	var buf bytes.Buffer
+	if err := tg.ToMermaid(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `["o/r#3 ..."]`) {
+		t.Errorf("expected an incomplete node in:\n%s", buf.String())
+	}
+
+	// resume without the deadline
+	fs.latency = nil
+	err = tg.AccumulateFrom(context.Background(), fs, &IssueRef{"o", "r", 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tg.Incomplete) != 0 || len(tg.Refs) != 6 {
+		t.Errorf("expected a complete graph after resuming, got %d nodes and %v incomplete", len(tg.Refs), tg.Incomplete)
+	}
+}
+
+func TestAccumulateError(t *testing.T) {
+	fs := newFakeSource()
+	fs.add("o", "r", 1, "root", github_open, "#2", "#404")
+	fs.add("o", "r", 2, "child", github_open)
+
+	tg := TaskGraph{}
+	err := tg.AccumulateFrom(context.Background(), fs, &IssueRef{"o", "r", 1})
+	if err == nil {
+		t.Fatal("expected an error for the missing issue")
+	}
+}
+
+func TestAccumulateLogsPerInstance(t *testing.T) {
+	var info, trace bytes.Buffer
+
+	tgInfo := New(WithLogger(slog.New(slog.NewTextHandler(&info, nil))))
+	tgTrace := New(WithLogger(slog.New(slog.NewTextHandler(&trace, &slog.HandlerOptions{Level: LevelTrace}))))
+
+	for _, tg := range []*TaskGraph{tgInfo, tgTrace} {
+		if err := tg.AccumulateFrom(context.Background(), diamond(), &IssueRef{"o", "r", 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !strings.Contains(info.String(), "issue=o/r#6") {
+		t.Errorf("expected traversal to be logged at info:\n%s", info.String())
+	}
+	if strings.Contains(info.String(), "tasklist") {
+		t.Errorf("expected no trace output at info:\n%s", info.String())
+	}
+	if !strings.Contains(trace.String(), "[tasklist]") {
+		t.Errorf("expected issue bodies to be logged at trace:\n%s", trace.String())
+	}
+}
+
+func TestAccumulateProgress(t *testing.T) {
+	var reports []Progress
+	tg := New(WithProgress(func(p Progress) {
+		reports = append(reports, p)
+	}))
+	if err := tg.AccumulateFrom(context.Background(), diamond(), &IssueRef{"o", "r", 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(reports) != 6 {
+		t.Fatalf("expected a report per fetch, got %v", reports)
+	}
+	last := reports[len(reports)-1]
+	if last.Fetched != 6 || last.Pending != 0 || last.Failed != 0 || last.RateRemaining != -1 {
+		t.Errorf("unexpected final progress %+v", last)
+	}
+}
+
+// partialSource answers without rate limit headers, and cannot fetch the
+// fields of o/r#5.
+type partialSource struct{ *fakeSource }
+
+func (ps partialSource) Get(ctx context.Context, owner string, repo string, number int) (*github.Issue, *github.Response, error) {
+	issue, _, err := ps.fakeSource.Get(ctx, owner, repo, number)
+	return issue, &github.Response{Response: &http.Response{Header: http.Header{}}}, err
+}
+
+func (ps partialSource) Fields(ctx context.Context, owner string, repo string, number int) (map[string]string, error) {
+	if number == 5 {
+		return nil, fmt.Errorf("no fields for #%d", number)
+	}
+	return map[string]string{"Size": "M"}, nil
+}
+
+func TestAccumulateProgressFailures(t *testing.T) {
+	var last Progress
+	tg := New(WithFields("Size"), WithProgress(func(p Progress) {
+		last = p
+	}))
+	if err := tg.AccumulateFrom(context.Background(), partialSource{diamond()}, &IssueRef{"o", "r", 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the traversal carries on past the fields it could not fetch
+	if last.Fetched != 6 || last.Failed != 1 || last.RateRemaining != -1 {
+		t.Errorf("unexpected final progress %+v", last)
+	}
+	if tg.Refs["o/r#5"].Fields != nil || tg.Refs["o/r#6"].Fields["Size"] != "M" {
+		t.Errorf("unexpected fields %v and %v", tg.Refs["o/r#5"].Fields, tg.Refs["o/r#6"].Fields)
+	}
+}
+
+// wide builds a tree of the given fan-out and depth, rooted at o/r#1.
+func wide(fanout int, depth int) *fakeSource {
+	fs := newFakeSource()
+	next := 1
+	var build func(level int) int
+	build = func(level int) int {
+		n := next
+		next++
+		var children []string
+		if level < depth {
+			for i := 0; i < fanout; i++ {
+				children = append(children, fmt.Sprintf("#%d", build(level+1)))
+			}
+		}
+		fs.add("o", "r", n, fmt.Sprintf("task %d", n), github_open, children...)
+		return n
+	}
+	build(0)
+	return fs
+}
+
+func BenchmarkAccumulate(b *testing.B) {
+	for _, workers := range []int{1, 10, 50} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			fs := wide(4, 3)
+			// mostly quick fetches, with the occasional straggler
+			fs.latency = func(ref string) time.Duration {
+				if strings.HasSuffix(ref, "7") {
+					return 5 * time.Millisecond
+				}
+				return 500 * time.Microsecond
+			}
+			for i := 0; i < b.N; i++ {
+				tg := New(WithWorkers(workers))
+				if err := tg.AccumulateFrom(context.Background(), fs, &IssueRef{"o", "r", 1}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package taskgraph
+
+import (
+	"math"
+)
+
+// CriticalPath is the longest chain of open tasks through the tasklists,
+// which bounds how soon the tasks at its head can be done.
+type CriticalPath struct {
+	// Path lists the tasks of the chain in order, from a task that no open
+	// task lists, e.g. a root, down to a task that lists no open tasks.
+	Path []string `json:"path"`
+	// Effort is the sum of the estimates of the tasks on the path, or their
+	// number if not weighted by estimates.
+	Effort float64 `json:"effort"`
+	// Slack is how much the longest chain through each open task could grow
+	// before it would be critical, and is 0 for the tasks on the path.
+	Slack map[string]float64 `json:"slack"`
+}
+
+// WithCriticalPath highlights the critical path, see CriticalPath, in
+// Mermaid graphs.
+func WithCriticalPath(show bool) RenderOption {
+	return func(r *render) {
+		r.critical = show
+	}
+}
+
+// CriticalPath finds the longest chain of open tasks, each weighed by its
+// estimate if any estimate fields are given, as by WithEstimates, or else
+// counted. Closed tasks, having been done, break chains. Cycles are broken
+// where they are first met, walking from the roots.
+func (tg *TaskGraph) CriticalPath(estimates []string) CriticalPath {
+	cp := CriticalPath{Slack: make(map[string]float64)}
+
+	// the open tasks, with their weights, roots first
+	weight := make(map[string]float64)
+	var refs []string
+	for _, ref := range append(append(append([]string(nil), tg.Roots...), sortedKeys(tg.Refs)...), sortedKeys(tg.Incomplete)...) {
+		if _, ok := weight[ref]; ok {
+			continue
+		}
+		if w, closed, ok := tg.weight(ref, estimates); ok && !closed {
+			weight[ref] = w
+			refs = append(refs, ref)
+		}
+	}
+
+	// topological order, in reverse, leaving out the edges that close cycles
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(refs))
+	next := make(map[string][]string, len(refs))
+	var order []string
+	var visit func(ref string)
+	visit = func(ref string) {
+		state[ref] = visiting
+		for _, to := range tg.tasklist(ref) {
+			if _, open := weight[to]; !open || state[to] == visiting {
+				continue
+			}
+			next[ref] = append(next[ref], to)
+			if state[to] == unvisited {
+				visit(to)
+			}
+		}
+		state[ref] = visited
+		order = append(order, ref)
+	}
+	for _, ref := range refs {
+		if state[ref] == unvisited {
+			visit(ref)
+		}
+	}
+
+	// the longest chain from each task down, preferring more tasks on ties
+	// so that chains run on through tasks without estimates
+	type chain struct {
+		effort float64
+		tasks  int
+	}
+	longer := func(a, b chain) bool {
+		return a.effort > b.effort || (a.effort == b.effort && a.tasks > b.tasks)
+	}
+	tail := make(map[string]chain, len(refs))
+	best := make(map[string]string, len(refs))
+	for _, ref := range order {
+		var c chain
+		for _, to := range next[ref] {
+			if longer(tail[to], c) {
+				c, best[ref] = tail[to], to
+			}
+		}
+		tail[ref] = chain{c.effort + weight[ref], c.tasks + 1}
+	}
+
+	// the longest chain from the top to each task
+	head := make(map[string]float64, len(refs))
+	for i := len(order) - 1; i >= 0; i-- {
+		ref := order[i]
+		for _, to := range next[ref] {
+			head[to] = math.Max(head[to], head[ref]+weight[ref])
+		}
+	}
+
+	// the path starts from the task with the longest chain
+	var start string
+	for i := len(order) - 1; i >= 0; i-- {
+		if ref := order[i]; len(start) == 0 || longer(tail[ref], tail[start]) {
+			start = ref
+		}
+	}
+	if len(start) == 0 {
+		return cp
+	}
+	cp.Effort = tail[start].effort
+	for ref := start; len(ref) > 0; ref = best[ref] {
+		cp.Path = append(cp.Path, ref)
+	}
+	for _, ref := range refs {
+		slack := cp.Effort - head[ref] - tail[ref].effort
+		cp.Slack[ref] = math.Max(0, math.Round(slack*1e6)/1e6)
+	}
+	return cp
+}
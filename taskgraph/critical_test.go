@@ -0,0 +1,98 @@
+package taskgraph
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCriticalPath(t *testing.T) {
+	// the release also lists a large task
+	tg := New()
+	addTask(tg, "o/r#1", `Release "one"`, StateOpen)
+	addTask(tg, "o/r#2", "Feature", StateClosed).StateReason = "not_planned"
+	addTask(tg, "o/s#3", "Docs", StateOpen).Labels = []string{"in progress"}
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/r#2"})
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/s#3"})
+	tg.AddEdge(Edge{From: "o/s#3", To: "o/s#4"})
+	tg.AddEdge(Edge{From: "o/r#2", To: "o/s#3", Kind: EdgeBlocks})
+	tg.Incomplete["o/s#4"] = &IssueRef{"o", "s", 4}
+	link(tg, "o/r#1", "o/r#7")
+	tg.Refs["o/r#7"].Fields = map[string]string{"Estimate": "5"}
+	tg.Refs["o/s#3"].Fields = map[string]string{"Estimate": "1"}
+
+	// counting, the closed feature breaks its chain
+	cp := tg.CriticalPath(nil)
+	if want := []string{"o/r#1", "o/s#3", "o/s#4"}; !reflect.DeepEqual(cp.Path, want) {
+		t.Errorf("expected path %v, got %v", want, cp.Path)
+	}
+	if cp.Effort != 3 {
+		t.Errorf("expected an effort of 3, got %v", cp.Effort)
+	}
+	want := map[string]float64{"o/r#1": 0, "o/s#3": 0, "o/s#4": 0, "o/r#7": 1}
+	if !reflect.DeepEqual(cp.Slack, want) {
+		t.Errorf("expected slack %v, got %v", want, cp.Slack)
+	}
+
+	// weighted, the unfetched task weighs nothing
+	cp = tg.CriticalPath(DefaultEstimateFields)
+	if want := []string{"o/r#1", "o/r#7"}; !reflect.DeepEqual(cp.Path, want) {
+		t.Errorf("expected path %v, got %v", want, cp.Path)
+	}
+	if cp.Effort != 5 || cp.Slack["o/s#3"] != 4 || cp.Slack["o/s#4"] != 4 {
+		t.Errorf("expected an effort of 5, and a slack of 4 for the docs, got %v and %v", cp.Effort, cp.Slack)
+	}
+
+	// only tasklists make chains
+	tg.AddEdge(Edge{From: "o/s#4", To: "o/r#7", Kind: EdgeBlocks})
+	if cp := tg.CriticalPath(nil); len(cp.Path) != 3 || cp.Effort != 3 {
+		t.Errorf("expected the blocks edge to be left out, got %+v", cp)
+	}
+
+	// cycles are broken
+	tg.AddEdge(Edge{From: "o/s#4", To: "o/r#1"})
+	if cp := tg.CriticalPath(nil); len(cp.Path) != 3 || cp.Path[0] != "o/r#1" {
+		t.Errorf("expected the cycle to be broken at the root, got %v", cp.Path)
+	}
+
+	// nothing open
+	if cp := New().CriticalPath(nil); cp.Path != nil || cp.Effort != 0 {
+		t.Errorf("expected no path, got %+v", cp)
+	}
+}
+
+func TestCriticalPathMermaid(t *testing.T) {
+	tg := New()
+	addTask(tg, "o/r#1", `Release "one"`, StateOpen)
+	addTask(tg, "o/r#2", "Feature", StateClosed).StateReason = "not_planned"
+	addTask(tg, "o/s#3", "Docs", StateOpen).Labels = []string{"in progress"}
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/r#2"})
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/s#3"})
+	tg.AddEdge(Edge{From: "o/s#3", To: "o/s#4"})
+	tg.AddEdge(Edge{From: "o/r#2", To: "o/s#3", Kind: EdgeBlocks})
+	tg.Incomplete["o/s#4"] = &IssueRef{"o", "s", 4}
+
+	var buf bytes.Buffer
+	if err := tg.ToMermaid(&buf, WithCriticalPath(true)); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"classDef critical stroke:#d00000,stroke-width:4px\n",
+		"\tclass tg_o_r_1 critical;\n\tclass tg_o_s_3 critical;\n\tclass tg_o_s_4 critical;\n",
+		"\tlinkStyle 1,3 stroke:#d00000,stroke-width:4px;\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	if err := tg.ToMermaid(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "critical") {
+		t.Errorf("expected no critical path unless asked for, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,341 @@
+package taskgraph
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// The columns of the CSV tables, unless set WithColumns. The RollupColumns
+// follow the default node columns WithRollup.
+var (
+	DefaultNodeColumns = []string{"ref", "title", "state", "repo", "labels", "assignees", "milestone", "parents", "depth", "url"}
+	DefaultEdgeColumns = []string{"from", "to", "kind", "checked"}
+	RollupColumns      = []string{"rollup_closed", "rollup_total", "rollup_percent"}
+)
+
+// WithColumns sets the columns of the CSV node and edge tables, in place of
+// DefaultNodeColumns and DefaultEdgeColumns. The node columns may be any of
+// NodeColumns, and the edge columns any of EdgeColumns. Nil keeps the default.
+func WithColumns(nodes, edges []string) RenderOption {
+	return func(r *render) {
+		if nodes != nil {
+			r.nodeColumns = nodes
+		}
+		if edges != nil {
+			r.edgeColumns = edges
+		}
+	}
+}
+
+// WithSeparator sets the field separator of the CSV tables, e.g. '\t' for
+// TSV. The default is a comma.
+func WithSeparator(separator rune) RenderOption {
+	return func(r *render) {
+		r.separator = separator
+	}
+}
+
+// csvNode is a row of the node table.
+type csvNode struct {
+	*viewNode
+	Parents []*viewNode
+	Depth   int
+}
+
+// csvNodeColumns are the node columns, over and above the exported node
+// attributes.
+var csvNodeColumns = []exportAttr[*csvNode]{
+	{"ref", "string", func(n *csvNode) string { return n.Key }},
+	{"parents", "string", func(n *csvNode) string {
+		refs := make([]string, len(n.Parents))
+		for i, p := range n.Parents {
+			refs[i] = p.Key
+		}
+		return strings.Join(refs, ",")
+	}},
+	{"depth", "int", func(n *csvNode) string { return strconv.Itoa(n.Depth) }},
+}
+
+var csvEdgeColumns = []exportAttr[viewEdge]{
+	{"from", "string", func(e viewEdge) string { return e.From.Key }},
+	{"to", "string", func(e viewEdge) string { return e.To.Key }},
+}
+
+// NodeColumns lists the columns available to the CSV node table.
+func NodeColumns() []string {
+	var names []string
+	for _, c := range csvNodeColumns {
+		names = append(names, c.Name)
+	}
+	for _, a := range exportNodeAttrs {
+		names = append(names, a.Name)
+	}
+	return names
+}
+
+// EdgeColumns lists the columns available to the CSV edge table.
+func EdgeColumns() []string {
+	var names []string
+	for _, c := range csvEdgeColumns {
+		names = append(names, c.Name)
+	}
+	for _, a := range exportEdgeAttrs {
+		names = append(names, a.Name)
+	}
+	return names
+}
+
+func csvNodeColumn(name string) (exportAttr[*csvNode], bool) {
+	for _, c := range csvNodeColumns {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	for _, a := range exportNodeAttrs {
+		if a.Name == name {
+			value := a.value
+			return exportAttr[*csvNode]{a.Name, a.Type, func(n *csvNode) string { return value(n.viewNode) }}, true
+		}
+	}
+	return exportAttr[*csvNode]{}, false
+}
+
+func csvEdgeColumn(name string) (exportAttr[viewEdge], bool) {
+	for _, c := range append(csvEdgeColumns, exportEdgeAttrs...) {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return exportAttr[viewEdge]{}, false
+}
+
+// csvCell guards a cell against being read as a formula by spreadsheets.
+func csvCell(text string) string {
+	if len(text) > 0 && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		if _, err := strconv.ParseFloat(text, 64); err != nil {
+			return "'" + text
+		}
+	}
+	return text
+}
+
+// writeCSV writes a table with a header row.
+func writeCSV(writer io.Writer, separator rune, header []string, rows [][]string) error {
+	w := csv.NewWriter(writer)
+	w.Comma = separator
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		for i := range row {
+			row[i] = csvCell(row[i])
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// csvNodes lists the rows of the node table in tasklist order, from the roots,
+// with the fewest steps from a root to each task.
+func (tg *TaskGraph) csvNodes(v *view) []*csvNode {
+	children := make(map[*viewNode][]*viewNode)
+	parents := make(map[*viewNode][]*viewNode)
+	for _, e := range v.Edges {
+		children[e.From] = append(children[e.From], e.To)
+		parents[e.To] = append(parents[e.To], e.From)
+	}
+	var rows []*csvNode
+	index := make(map[*viewNode]*csvNode)
+	var list func(items []*treeItem)
+	list = func(items []*treeItem) {
+		for _, item := range items {
+			if _, ok := index[item.viewNode]; !ok {
+				row := &csvNode{viewNode: item.viewNode, Parents: parents[item.viewNode], Depth: -1}
+				index[item.viewNode] = row
+				rows = append(rows, row)
+			}
+			list(item.Children)
+		}
+	}
+	roots := tg.tree(v, nil)
+	list(roots)
+
+	// breadth first from the roots, for the depths
+	var queue []*csvNode
+	for _, r := range roots {
+		index[r.viewNode].Depth = 0
+		queue = append(queue, index[r.viewNode])
+	}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for _, c := range children[n.viewNode] {
+			if row := index[c]; row.Depth < 0 {
+				row.Depth = n.Depth + 1
+				queue = append(queue, row)
+			}
+		}
+	}
+	return rows
+}
+
+// nodeTable lists the rows of the node table, and the types of its columns.
+func (tg *TaskGraph) nodeTable(cfg *render) ([]string, [][]string, error) {
+	columns := make([]exportAttr[*csvNode], len(cfg.nodeColumns))
+	types := make([]string, len(cfg.nodeColumns))
+	for i, name := range cfg.nodeColumns {
+		columns[i], _ = csvNodeColumn(name) // checked by newRender
+		types[i] = columns[i].Type
+	}
+
+	v, err := tg.view(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	var rows [][]string
+	for _, n := range tg.csvNodes(v) {
+		row := make([]string, len(columns))
+		for i, column := range columns {
+			row[i] = column.value(n)
+		}
+		rows = append(rows, row)
+	}
+	return types, rows, nil
+}
+
+// edgeTable lists the rows of the edge table, and the types of its columns.
+func (tg *TaskGraph) edgeTable(cfg *render) ([]string, [][]string, error) {
+	columns := make([]exportAttr[viewEdge], len(cfg.edgeColumns))
+	types := make([]string, len(cfg.edgeColumns))
+	for i, name := range cfg.edgeColumns {
+		columns[i], _ = csvEdgeColumn(name) // checked by newRender
+		types[i] = columns[i].Type
+	}
+
+	v, err := tg.view(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	var rows [][]string
+	for _, e := range v.Edges {
+		row := make([]string, len(columns))
+		for i, column := range columns {
+			row[i] = column.value(e)
+		}
+		rows = append(rows, row)
+	}
+	return types, rows, nil
+}
+
+// WriteCSV writes the tasks of the graph as a CSV table, one row per task,
+// for spreadsheets. The rows follow the tasklists from the roots in order.
+// Cells that a spreadsheet would read as a formula are quoted with a leading
+// apostrophe.
+func (tg *TaskGraph) WriteCSV(writer io.Writer, opts ...RenderOption) error {
+
+	cfg, err := newRender(opts...)
+	if err != nil {
+		return err
+	}
+	_, rows, err := tg.nodeTable(cfg)
+	if err != nil {
+		return err
+	}
+	return writeCSV(writer, cfg.separator, cfg.nodeColumns, rows)
+}
+
+// WriteEdgeCSV writes the edges of the graph as a CSV table, one row per
+// edge, to go with the table of WriteCSV.
+func (tg *TaskGraph) WriteEdgeCSV(writer io.Writer, opts ...RenderOption) error {
+
+	cfg, err := newRender(opts...)
+	if err != nil {
+		return err
+	}
+	_, rows, err := tg.edgeTable(cfg)
+	if err != nil {
+		return err
+	}
+	return writeCSV(writer, cfg.separator, cfg.edgeColumns, rows)
+}
+
+// jsonValue converts a cell to JSON by the type of its column, unset cells
+// being null.
+func jsonValue(typ, cell string) any {
+	switch {
+	case len(cell) == 0:
+		return nil
+	case typ == "int" || typ == "double":
+		return json.Number(cell)
+	case typ == "boolean":
+		return cell == "true"
+	}
+	return cell
+}
+
+// writeJSON writes a table as a JSON array with an object per row, keyed by
+// column, one per line.
+func writeJSON(writer io.Writer, header, types []string, rows [][]string) error {
+	w := &errWriter{w: writer}
+	w.printf("[")
+	for i, row := range rows {
+		if i > 0 {
+			w.printf(",")
+		}
+		w.printf("\n\t{")
+		for j, cell := range row {
+			key, _ := json.Marshal(header[j])
+			value, err := json.Marshal(jsonValue(types[j], cell))
+			if err != nil {
+				return err
+			}
+			if j > 0 {
+				w.printf(", ")
+			}
+			w.printf("%s: %s", key, value)
+		}
+		w.printf("}")
+	}
+	if len(rows) > 0 {
+		w.printf("\n")
+	}
+	w.printf("]\n")
+	return w.err
+}
+
+// WriteJSON writes the table of WriteCSV as JSON, an array with an object per
+// task, keyed by column. Numbers and booleans are typed, and unset cells are
+// null.
+func (tg *TaskGraph) WriteJSON(writer io.Writer, opts ...RenderOption) error {
+
+	cfg, err := newRender(opts...)
+	if err != nil {
+		return err
+	}
+	types, rows, err := tg.nodeTable(cfg)
+	if err != nil {
+		return err
+	}
+	return writeJSON(writer, cfg.nodeColumns, types, rows)
+}
+
+// WriteEdgeJSON writes the table of WriteEdgeCSV as JSON, to go with that of
+// WriteJSON.
+func (tg *TaskGraph) WriteEdgeJSON(writer io.Writer, opts ...RenderOption) error {
+
+	cfg, err := newRender(opts...)
+	if err != nil {
+		return err
+	}
+	types, rows, err := tg.edgeTable(cfg)
+	if err != nil {
+		return err
+	}
+	return writeJSON(writer, cfg.edgeColumns, types, rows)
+}
@@ -0,0 +1,48 @@
+package taskgraph
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteCSV(t *testing.T) {
+	tg := New()
+	addTask(tg, "o/r#1", `Release "one"`, StateOpen)
+	addTask(tg, "o/r#2", "Feature", StateClosed).StateReason = "not_planned"
+	addTask(tg, "o/s#3", "Docs", StateOpen).Labels = []string{"in progress"}
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/r#2"})
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/s#3"})
+	tg.AddEdge(Edge{From: "o/s#3", To: "o/s#4"})
+	tg.AddEdge(Edge{From: "o/r#2", To: "o/s#3", Kind: EdgeBlocks})
+	tg.Incomplete["o/s#4"] = &IssueRef{"o", "s", 4}
+	tg.Roots = []string{"o/r#1"}
+	tg.Refs["o/r#2"].Labels = []string{"a", "b"}
+	tg.Refs["o/r#2"].Title = "=SUM(A1)"
+
+	var buf bytes.Buffer
+	if err := tg.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `ref,title,state,repo,labels,assignees,milestone,parents,depth,url
+o/r#1,"Release ""one""",open,r,,,,,0,https://github.com/o/r/issues/1
+o/r#2,'=SUM(A1),closed,r,"a,b",,,o/r#1,1,https://github.com/o/r/issues/2
+o/s#3,Docs,open,s,in progress,,,"o/r#1,o/r#2",1,https://github.com/o/s/issues/3
+o/s#4,o/s#4 ...,,s,,,,o/s#3,2,https://github.com/o/s/issues/4
+`
+	if got := buf.String(); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+
+	buf.Reset()
+	if err := tg.WriteEdgeCSV(&buf, WithSeparator('\t'), WithColumns(nil, []string{"from", "to", "kind"})); err != nil {
+		t.Fatal(err)
+	}
+	want = "from\tto\tkind\no/r#1\to/r#2\ttasklist\no/r#1\to/s#3\ttasklist\no/r#2\to/s#3\tblocks\no/s#3\to/s#4\ttasklist\n"
+	if got := buf.String(); got != want {
+		t.Errorf("expected:\n%q\ngot:\n%q", want, got)
+	}
+
+	if err := tg.WriteCSV(&buf, WithColumns([]string{"ref", "nope"}, nil)); err == nil {
+		t.Error("expected an error for an unknown column")
+	}
+}
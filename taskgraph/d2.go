@@ -0,0 +1,104 @@
+package taskgraph
+
+import (
+	"io"
+	"strings"
+)
+
+// d2Quote quotes text as a D2 string.
+func d2Quote(text string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(text) + `"`
+}
+
+// d2Directions maps the graph direction onto a D2 direction.
+var d2Directions = map[string]string{
+	"TB": "down",
+	"BT": "up",
+	"LR": "right",
+	"RL": "left",
+}
+
+// ToD2 renders the graph as a D2 diagram, with a container per group of tasks,
+// by default per repository (see WithGrouping). Status classes are declared as
+// D2 classes, and nodes link to their issues.
+func (tg *TaskGraph) ToD2(writer io.Writer, opts ...RenderOption) error {
+
+	cfg, err := newRender(opts...)
+	if err != nil {
+		return err
+	}
+	v, err := tg.view(cfg)
+	if err != nil {
+		return err
+	}
+	w := &errWriter{w: writer}
+
+	w.printf("direction: %s\n", d2Directions[cfg.dir])
+	w.printf("title: %s {\n\tshape: text\n\tnear: top-center\n\tstyle.font-size: 24\n}\n", d2Quote(cfg.theme.Title))
+
+	// output classes
+	w.printf("\nclasses: {\n")
+	for _, class := range styledClasses() {
+		fill := cfg.theme.fill(class)
+		w.printf("\t%s: {\n\t\tstyle.fill: %s\n", class, d2Quote(fill))
+		if isDark(fill) {
+			w.printf("\t\tstyle.font-color: \"#ffffff\"\n")
+		}
+		if class == StatusIncomplete {
+			w.printf("\t\tstyle.stroke-dash: 5\n")
+		}
+		w.printf("\t}\n")
+	}
+	w.printf("}\n")
+
+	// output nodes, within their group containers
+	node := func(n *viewNode, indent string) {
+		w.printf("%s%s: %s {\n", indent, n.ID, d2Quote(v.rollupLabel(n, false)))
+		if len(n.URL) > 0 {
+			w.printf("%s\tlink: %s\n", indent, d2Quote(n.URL))
+			w.printf("%s\ttooltip: %s\n", indent, d2Quote("Open "+n.Key))
+		}
+		if len(n.Class) > 0 {
+			w.printf("%s\tclass: %s\n", indent, n.Class)
+		}
+		w.printf("%s}\n", indent)
+	}
+	var container func(g *viewGroup, indent string)
+	container = func(g *viewGroup, indent string) {
+		w.printf("%s%s: %s {\n", indent, g.ID, d2Quote(g.Name))
+		w.printf("%s\tstyle.fill: %s\n", indent, d2Quote(cfg.theme.fill(PaletteGroup)))
+		for _, n := range g.Nodes {
+			node(n, indent+"\t")
+		}
+		for _, sub := range g.Groups {
+			container(sub, indent+"\t")
+		}
+		w.printf("%s}\n", indent)
+	}
+	if len(v.Loose) > 0 {
+		w.printf("\n")
+	}
+	for _, n := range v.Loose {
+		node(n, "")
+	}
+	for _, g := range v.Groups {
+		w.printf("\n")
+		container(g, "")
+	}
+
+	// output edges, which must name the containers of nested nodes
+	key := func(n *viewNode) string {
+		return strings.Join(append(n.Group.Path(), n.ID), ".")
+	}
+	w.printf("\n")
+	for _, e := range v.Edges {
+		w.printf("%s -> %s", key(e.From), key(e.To))
+		if label := e.Label(); len(label) > 0 {
+			w.printf(": %s", d2Quote(label))
+		}
+		w.printf("\n")
+	}
+
+	return w.err
+}
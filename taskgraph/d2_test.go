@@ -0,0 +1,37 @@
+package taskgraph
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestToD2(t *testing.T) {
+	tg := New()
+	addTask(tg, "o/r#1", `Release "one"`, StateOpen)
+	addTask(tg, "o/r#2", "Feature", StateClosed).StateReason = "not_planned"
+	addTask(tg, "o/s#3", "Docs", StateOpen).Labels = []string{"in progress"}
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/r#2"})
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/s#3"})
+	tg.AddEdge(Edge{From: "o/s#3", To: "o/s#4"})
+	tg.AddEdge(Edge{From: "o/r#2", To: "o/s#3", Kind: EdgeBlocks})
+	tg.Incomplete["o/s#4"] = &IssueRef{"o", "s", 4}
+
+	var buf bytes.Buffer
+	if err := tg.ToD2(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"direction: down\n",
+		"\tincomplete: {\n\t\tstyle.fill: \"#fff\"\n\t\tstyle.stroke-dash: 5\n",
+		"\ttg_o_r_1: \"Release \\\"one\\\"\" {\n\t\tlink: \"https://github.com/o/r/issues/1\"\n",
+		"\t\tclass: abandoned\n",
+		"tg_group_o_r.tg_o_r_1 -> tg_group_o_s.tg_o_s_3\n",
+		"tg_group_o_r.tg_o_r_2 -> tg_group_o_s.tg_o_s_3: \"blocks\"\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+}
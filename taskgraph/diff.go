@@ -0,0 +1,347 @@
+package taskgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// State transitions reported by Diff.
+const (
+	TransitionOpened   = "opened"   // added, and open
+	TransitionClosed   = "closed"   // from open to closed
+	TransitionReopened = "reopened" // from closed to open
+	TransitionChanged  = "changed"  // any other change, e.g. to or from a state that is not known
+)
+
+// TaskSummary identifies a task within a GraphDiff.
+type TaskSummary struct {
+	ID    string `json:"id"`
+	URL   string `json:"url,omitempty"`
+	Title string `json:"title"`
+	State string `json:"state"`
+}
+
+// StateChange records a task whose state differs between two graphs.
+type StateChange struct {
+	TaskSummary
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Transition string `json:"transition"`
+}
+
+// TitleChange records a task whose title differs between two graphs.
+type TitleChange struct {
+	TaskSummary
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Reparent records a task whose parents differ between two graphs.
+type Reparent struct {
+	TaskSummary
+	From []string `json:"from"`
+	To   []string `json:"to"`
+}
+
+// EdgeChange records a tasklist edge that was added or removed.
+type EdgeChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+// GraphDiff lists what changed from one task graph to another, typically two
+// snapshots of the same plan taken at different times.
+type GraphDiff struct {
+	Added        []TaskSummary `json:"added"`
+	Removed      []TaskSummary `json:"removed"`
+	AddedEdges   []EdgeChange  `json:"added_edges"`
+	RemovedEdges []EdgeChange  `json:"removed_edges"`
+	States       []StateChange `json:"states"`
+	Titles       []TitleChange `json:"titles"`
+	Reparented   []Reparent    `json:"reparented"`
+
+	old *TaskGraph
+	new *TaskGraph
+}
+
+func summarise(t *Task) TaskSummary {
+	return TaskSummary{ID: t.ID, URL: t.URL, Title: t.Title, State: t.State}
+}
+
+// parents maps each task to the tasks that link to it, in sorted order.
+func (tg *TaskGraph) parents() map[string][]string {
+	ps := make(map[string][]string, len(tg.Refs))
+	for _, from := range sortedKeys(tg.Edges) {
+		for _, to := range tg.Edges[from] {
+			ps[to] = append(ps[to], from)
+		}
+	}
+	return ps
+}
+
+// Diff compares two task graphs, with changes reported from the old graph to
+// the new graph. All changes are listed in order of task reference.
+func Diff(old, new *TaskGraph) *GraphDiff {
+	d := &GraphDiff{
+		Added:        []TaskSummary{},
+		Removed:      []TaskSummary{},
+		AddedEdges:   []EdgeChange{},
+		RemovedEdges: []EdgeChange{},
+		States:       []StateChange{},
+		Titles:       []TitleChange{},
+		Reparented:   []Reparent{},
+		old:          old,
+		new:          new,
+	}
+
+	// nodes
+	oldParents := old.parents()
+	newParents := new.parents()
+	for _, k := range sortedKeys(new.Refs) {
+		n := new.Refs[k]
+		o, ok := old.Refs[k]
+		if !ok {
+			d.Added = append(d.Added, summarise(n))
+			if n.State == StateOpen {
+				d.States = append(d.States, StateChange{summarise(n), "", n.State, TransitionOpened})
+			}
+			continue
+		}
+		if o.State != n.State {
+			transition := TransitionChanged
+			switch {
+			case o.State == StateOpen && n.State == StateClosed:
+				transition = TransitionClosed
+			case o.State == StateClosed && n.State == StateOpen:
+				transition = TransitionReopened
+			}
+			d.States = append(d.States, StateChange{summarise(n), o.State, n.State, transition})
+		}
+		if o.Title != n.Title {
+			d.Titles = append(d.Titles, TitleChange{summarise(n), o.Title, n.Title})
+		}
+		if !slices.Equal(oldParents[k], newParents[k]) {
+			d.Reparented = append(d.Reparented, Reparent{summarise(n), oldParents[k], newParents[k]})
+		}
+	}
+	for _, k := range sortedKeys(old.Refs) {
+		if _, ok := new.Refs[k]; !ok {
+			d.Removed = append(d.Removed, summarise(old.Refs[k]))
+		}
+	}
+
+	// edges
+	for _, from := range sortedKeys(new.Edges) {
+		for _, to := range new.Edges[from] {
+			if !old.HasEdge(from, to) {
+				d.AddedEdges = append(d.AddedEdges, EdgeChange{from, to, new.Edge(from, to).Kind})
+			}
+		}
+	}
+	for _, from := range sortedKeys(old.Edges) {
+		for _, to := range old.Edges[from] {
+			if !new.HasEdge(from, to) {
+				d.RemovedEdges = append(d.RemovedEdges, EdgeChange{from, to, old.Edge(from, to).Kind})
+			}
+		}
+	}
+
+	return d
+}
+
+// Empty reports whether the two graphs are equivalent.
+func (d *GraphDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 &&
+		len(d.AddedEdges) == 0 && len(d.RemovedEdges) == 0 &&
+		len(d.States) == 0 && len(d.Titles) == 0 && len(d.Reparented) == 0
+}
+
+// WriteJSON writes the diff in its machine readable form.
+func (d *GraphDiff) WriteJSON(writer io.Writer) error {
+	enc := json.NewEncoder(writer)
+	enc.SetIndent("", "  ")
+	return enc.Encode(d)
+}
+
+func markdownLink(s TaskSummary) string {
+	if len(s.URL) == 0 {
+		return s.ID
+	}
+	return fmt.Sprintf("[%s](%s)", s.ID, s.URL)
+}
+
+func markdownRefs(refs []string) string {
+	if len(refs) == 0 {
+		return "_none_"
+	}
+	return strings.Join(refs, ", ")
+}
+
+func markdownState(state string) string {
+	if len(state) == 0 {
+		return "_unknown_"
+	}
+	return state
+}
+
+// WriteMarkdown writes a human readable summary of the diff.
+func (d *GraphDiff) WriteMarkdown(writer io.Writer) error {
+	w := &errWriter{w: writer}
+
+	w.printf("# Task Graph Changes\n")
+	if d.Empty() {
+		w.printf("\nNo changes.\n")
+		return w.err
+	}
+
+	section := func(title string, n int) bool {
+		if n == 0 {
+			return false
+		}
+		w.printf("\n## %s\n\n", title)
+		return true
+	}
+	if section("Added issues", len(d.Added)) {
+		for _, s := range d.Added {
+			w.printf("- %s %s (%s)\n", markdownLink(s), markdownEscape(s.Title), s.State)
+		}
+	}
+	if section("Removed issues", len(d.Removed)) {
+		for _, s := range d.Removed {
+			w.printf("- %s %s (%s)\n", markdownLink(s), markdownEscape(s.Title), s.State)
+		}
+	}
+	if section("State changes", len(d.States)) {
+		for _, c := range d.States {
+			from := markdownState(c.From)
+			if c.Transition == TransitionOpened {
+				from = "_new_"
+			}
+			w.printf("- %s %s: %s (%s → %s)\n", markdownLink(c.TaskSummary), markdownEscape(c.Title), c.Transition, from, markdownState(c.To))
+		}
+	}
+	if section("Title changes", len(d.Titles)) {
+		for _, c := range d.Titles {
+			w.printf("- %s: \"%s\" → \"%s\"\n", markdownLink(c.TaskSummary), markdownEscape(c.From), markdownEscape(c.To))
+		}
+	}
+	if section("Added edges", len(d.AddedEdges)) {
+		for _, e := range d.AddedEdges {
+			w.printf("- %s → %s\n", e.From, e.To)
+		}
+	}
+	if section("Removed edges", len(d.RemovedEdges)) {
+		for _, e := range d.RemovedEdges {
+			w.printf("- %s → %s\n", e.From, e.To)
+		}
+	}
+	if section("Re-parented issues", len(d.Reparented)) {
+		for _, c := range d.Reparented {
+			w.printf("- %s %s: %s → %s\n", markdownLink(c.TaskSummary), markdownEscape(c.Title), markdownRefs(c.From), markdownRefs(c.To))
+		}
+	}
+	return w.err
+}
+
+// ToMermaid renders the union of both graphs as a Mermaid flowchart, with
+// added, removed and changed tasks coloured differently. Added edges are drawn
+// thick, and removed edges dotted.
+func (d *GraphDiff) ToMermaid(writer io.Writer, opts ...RenderOption) error {
+	cfg, err := newRender(opts...)
+	if err != nil {
+		return err
+	}
+	w := &errWriter{w: writer}
+	id := newNodeIDs(len(d.new.Refs)).id
+
+	// gather the union of tasks, and how each was changed
+	tasks := make(map[string]*Task, len(d.new.Refs))
+	for k, t := range d.old.Refs {
+		tasks[k] = t
+	}
+	for k, t := range d.new.Refs {
+		tasks[k] = t
+	}
+	class := make(map[string]string, len(tasks))
+	for _, s := range d.Removed {
+		class[s.ID] = "removed"
+	}
+	for _, c := range d.States {
+		class[c.ID] = "changed"
+	}
+	for _, c := range d.Titles {
+		class[c.ID] = "changed"
+	}
+	for _, c := range d.Reparented {
+		class[c.ID] = "changed"
+	}
+	// added tasks are also reported as opened, but are coloured as added
+	for _, s := range d.Added {
+		class[s.ID] = "added"
+	}
+
+	w.printf(`---
+title: Task Graph Changes
+---
+
+flowchart
+
+subgraph Tasks
+
+	direction %s
+`, cfg.dir)
+
+	// each owner/repo is a separate subgraph
+	repos := make(map[string][]string)
+	for _, k := range sortedKeys(tasks) {
+		t := tasks[k]
+		repo := t.Owner + "/" + t.Repo
+		repos[repo] = append(repos[repo], k)
+	}
+	for _, repo := range sortedKeys(repos) {
+		w.printf("\n\tsubgraph %s[\"%s\"]\n\n", id("repo:"+repo), mermaidEscape(repo))
+		for _, k := range repos[repo] {
+			t := tasks[k]
+			kid := id(k)
+			w.printf("\t\t%s[\"%s\"]\n", kid, mermaidEscape(t.Title))
+			if len(t.URL) > 0 {
+				w.printf("\t\tclick %s href \"%s\" \"Open %s\"\n", kid, t.URL, t.ID)
+			}
+		}
+		w.printf("\n\tend\n")
+	}
+
+	// output edges, first those in the new graph and then those removed
+	w.printf("\n")
+	for _, from := range sortedKeys(d.new.Edges) {
+		for _, to := range d.new.Edges[from] {
+			arrow := "-->"
+			if !d.old.HasEdge(from, to) {
+				arrow = "==>"
+			}
+			w.printf("\t%s %s %s\n", id(from), arrow, id(to))
+		}
+	}
+	for _, e := range d.RemovedEdges {
+		w.printf("\t%s -.-> %s\n", id(e.From), id(e.To))
+	}
+
+	w.printf(`
+end
+
+classDef tasks fill:#fff
+classDef added fill:#37e519
+classDef removed fill:#f55a00,stroke-dasharray:5 5
+classDef changed fill:#e5b104
+
+class Tasks tasks;
+`)
+	for _, k := range sortedKeys(class) {
+		w.printf("\tclass %s %s;\n", id(k), class[k])
+	}
+	return w.err
+}
@@ -0,0 +1,120 @@
+package taskgraph
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func diffFixture() (*TaskGraph, *TaskGraph) {
+	old := New()
+	addTask(old, "o/r#1", "Release", StateOpen)
+	addTask(old, "o/r#2", "Feature", StateOpen)
+	addTask(old, "o/r#3", "Bug", StateClosed)
+	addTask(old, "o/r#4", "Dropped", StateOpen)
+	addTask(old, "o/r#5", "Sub", StateOpen)
+	addTask(old, "o/r#7", "Imported", "")
+	addTask(old, "o/r#8", "Migrated", "")
+	old.AddEdge(Edge{From: "o/r#1", To: "o/r#2"})
+	old.AddEdge(Edge{From: "o/r#1", To: "o/r#3"})
+	old.AddEdge(Edge{From: "o/r#1", To: "o/r#4"})
+	old.AddEdge(Edge{From: "o/r#2", To: "o/r#5"})
+
+	new := New()
+	addTask(new, "o/r#1", "Release", StateOpen)
+	addTask(new, "o/r#2", "Feature One", StateClosed)
+	addTask(new, "o/r#3", "Bug", StateOpen)
+	addTask(new, "o/r#5", "Sub", StateOpen)
+	addTask(new, "o/r#6", "New *and* [improved]", StateOpen)
+	addTask(new, "o/r#7", "Imported", StateOpen)
+	addTask(new, "o/r#8", "Migrated", StateClosed)
+	new.AddEdge(Edge{From: "o/r#1", To: "o/r#2"})
+	new.AddEdge(Edge{From: "o/r#1", To: "o/r#3"})
+	new.AddEdge(Edge{From: "o/r#1", To: "o/r#6"})
+	new.AddEdge(Edge{From: "o/r#3", To: "o/r#5"})
+	return old, new
+}
+
+func TestDiff(t *testing.T) {
+	d := Diff(diffFixture())
+
+	ids := func(ss []TaskSummary) string {
+		var out []string
+		for _, s := range ss {
+			out = append(out, s.ID)
+		}
+		return strings.Join(out, ",")
+	}
+	if got := ids(d.Added); got != "o/r#6" {
+		t.Errorf("added: %v", got)
+	}
+	if got := ids(d.Removed); got != "o/r#4" {
+		t.Errorf("removed: %v", got)
+	}
+	var transitions []string
+	for _, c := range d.States {
+		transitions = append(transitions, c.Transition)
+	}
+	if got := strings.Join(transitions, ","); got != "closed,reopened,opened,changed,changed" {
+		t.Errorf("states: %v", got)
+	}
+	if len(d.Titles) != 1 || d.Titles[0].From != "Feature" || d.Titles[0].To != "Feature One" {
+		t.Errorf("titles: %+v", d.Titles)
+	}
+	if len(d.AddedEdges) != 2 || len(d.RemovedEdges) != 2 {
+		t.Errorf("edges: +%+v -%+v", d.AddedEdges, d.RemovedEdges)
+	}
+	if len(d.Reparented) != 1 || d.Reparented[0].ID != "o/r#5" ||
+		d.Reparented[0].From[0] != "o/r#2" || d.Reparented[0].To[0] != "o/r#3" {
+		t.Errorf("reparented: %+v", d.Reparented)
+	}
+
+	if !Diff(New(), New()).Empty() {
+		t.Errorf("expected no changes between empty graphs")
+	}
+}
+
+func TestDiffOutputs(t *testing.T) {
+	d := Diff(diffFixture())
+
+	var md bytes.Buffer
+	if err := d.WriteMarkdown(&md); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"## Added issues\n\n- [o/r#6](https://github.com/o/r/issues/6) New \\*and\\* \\[improved\\] (open)\n",
+		"## State changes\n\n- [o/r#2](https://github.com/o/r/issues/2) Feature One: closed (open → closed)\n",
+		"- [o/r#6](https://github.com/o/r/issues/6) New \\*and\\* \\[improved\\]: opened (_new_ → open)\n",
+		"- [o/r#7](https://github.com/o/r/issues/7) Imported: changed (_unknown_ → open)\n",
+		"- [o/r#8](https://github.com/o/r/issues/8) Migrated: changed (_unknown_ → closed)\n",
+		"## Title changes\n\n- [o/r#2](https://github.com/o/r/issues/2): \"Feature\" → \"Feature One\"\n",
+		"## Re-parented issues\n\n- [o/r#5](https://github.com/o/r/issues/5) Sub: o/r#2 → o/r#3\n",
+	} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("expected %q in:\n%s", want, md.String())
+		}
+	}
+
+	var js bytes.Buffer
+	if err := d.WriteJSON(&js); err != nil {
+		t.Fatal(err)
+	}
+	var back GraphDiff
+	if err := json.Unmarshal(js.Bytes(), &back); err != nil {
+		t.Fatal(err)
+	}
+	if len(back.Added) != 1 || len(back.RemovedEdges) != 2 {
+		t.Errorf("unexpected JSON round trip %+v", back)
+	}
+
+	var mm bytes.Buffer
+	if err := d.ToMermaid(&mm); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"==>", "-.->", "\tclass tg_o_r_6 added;\n", "removed;", "changed;"} {
+		if !strings.Contains(mm.String(), want) {
+			t.Errorf("expected %q in:\n%s", want, mm.String())
+		}
+	}
+}
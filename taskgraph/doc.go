@@ -0,0 +1,21 @@
+// Package taskgraph builds directed graphs of GitHub issues from the
+// `[tasklist]` blocks embedded in their bodies, and renders them.
+//
+// A graph is created with New, populated by walking the tasklists reachable
+// from one or more root issues with Accumulate (or AccumulateFrom for other
+// issue sources), and then rendered, e.g. with ToMermaid:
+//
+//	tg := taskgraph.New(taskgraph.WithSkipClosed(true))
+//	err := tg.Accumulate(ctx, client, &taskgraph.IssueRef{Owner: "resystems-io", Repo: "task-graph", Number: 1})
+//	...
+//	err = tg.ToMermaid(os.Stdout, taskgraph.WithDirection("LR"))
+//
+// # Compatibility
+//
+// The exported API follows semantic versioning, as tracked by Version and the
+// module tags. Within a major version, exported identifiers will not be
+// removed or changed incompatibly, and options will only be added.
+package taskgraph
+
+// Version is the semantic version of this package's API.
+const Version = "0.1.0"
@@ -0,0 +1,108 @@
+package taskgraph
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+// dotQuote quotes text as a DOT string.
+func dotQuote(text string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(text) + `"`
+}
+
+// isDark reports whether a #rgb or #rrggbb colour needs light text.
+func isDark(colour string) bool {
+	hex := strings.TrimPrefix(colour, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return false
+	}
+	rgb, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return false
+	}
+	r, g, b := (rgb>>16)&0xff, (rgb>>8)&0xff, rgb&0xff
+	// perceived brightness, as per ITU-R BT.601
+	return (299*r+587*g+114*b)/1000 < 128
+}
+
+// ToDot renders the graph in the Graphviz DOT language, with a cluster per
+// group of tasks, by default per repository (see WithGrouping). Nodes carry
+// URL attributes, so that SVG output is clickable.
+func (tg *TaskGraph) ToDot(writer io.Writer, opts ...RenderOption) error {
+
+	cfg, err := newRender(opts...)
+	if err != nil {
+		return err
+	}
+	v, err := tg.view(cfg)
+	if err != nil {
+		return err
+	}
+	w := &errWriter{w: writer}
+
+	w.printf("digraph %s {\n", dotQuote(cfg.theme.Title))
+	w.printf("\trankdir=%s;\n", cfg.dir)
+	w.printf("\tnode [shape=box, style=\"rounded,filled\", fillcolor=\"#ffffff\", fontname=\"Helvetica\"];\n")
+	w.printf("\tedge [color=\"#555555\"];\n")
+
+	// output nodes, within their clusters
+	node := func(n *viewNode, indent string) {
+		attrs := []string{"label=" + dotQuote(v.rollupLabel(n, false))}
+		if len(n.URL) > 0 {
+			attrs = append(attrs, "URL="+dotQuote(n.URL), "tooltip="+dotQuote("Open "+n.Key), "target=\"_top\"")
+		}
+		if fill := n.Fill; len(fill) > 0 {
+			attrs = append(attrs, "fillcolor="+dotQuote(fill))
+			if isDark(fill) {
+				attrs = append(attrs, "fontcolor=\"#ffffff\"")
+			}
+		}
+		if n.Class == StatusIncomplete {
+			attrs = append(attrs, "style=\"rounded,filled,dashed\"")
+		}
+		if len(n.Class) > 0 {
+			attrs = append(attrs, "class="+dotQuote(n.Class))
+		}
+		w.printf("%s%s [%s];\n", indent, n.ID, strings.Join(attrs, ", "))
+	}
+	var cluster func(g *viewGroup, indent string)
+	cluster = func(g *viewGroup, indent string) {
+		w.printf("\n%ssubgraph %s {\n", indent, dotQuote("cluster_"+g.ID))
+		w.printf("%s\tlabel=%s;\n", indent, dotQuote(g.Name))
+		w.printf("%s\tstyle=\"rounded,filled\";\n%s\tfillcolor=%s;\n%s\tcolor=\"#999999\";\n\n", indent, indent, dotQuote(cfg.theme.fill(PaletteGroup)), indent)
+		for _, n := range g.Nodes {
+			node(n, indent+"\t")
+		}
+		for _, sub := range g.Groups {
+			cluster(sub, indent+"\t")
+		}
+		w.printf("%s}\n", indent)
+	}
+	if len(v.Loose) > 0 {
+		w.printf("\n")
+	}
+	for _, n := range v.Loose {
+		node(n, "\t")
+	}
+	for _, g := range v.Groups {
+		cluster(g, "\t")
+	}
+
+	// output edges
+	w.printf("\n")
+	for _, e := range v.Edges {
+		if label := e.Label(); len(label) > 0 {
+			w.printf("\t%s -> %s [label=%s];\n", e.From.ID, e.To.ID, dotQuote(label))
+		} else {
+			w.printf("\t%s -> %s;\n", e.From.ID, e.To.ID)
+		}
+	}
+
+	w.printf("}\n")
+	return w.err
+}
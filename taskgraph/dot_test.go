@@ -0,0 +1,77 @@
+package taskgraph
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func renderFixture() *TaskGraph {
+	tg := New()
+	addTask(tg, "o/r#1", `Release "one"`, StateOpen)
+	addTask(tg, "o/r#2", "Feature", StateClosed).StateReason = "not_planned"
+	addTask(tg, "o/s#3", "Docs", StateOpen).Labels = []string{"in progress"}
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/r#2"})
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/s#3"})
+	tg.AddEdge(Edge{From: "o/s#3", To: "o/s#4"})
+	tg.AddEdge(Edge{From: "o/r#2", To: "o/s#3", Kind: EdgeBlocks})
+	tg.Incomplete["o/s#4"] = &IssueRef{"o", "s", 4}
+	return tg
+}
+
+func TestToDot(t *testing.T) {
+	tg := renderFixture()
+
+	var buf bytes.Buffer
+	if err := tg.ToDot(&buf, WithDirection("LR")); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"\trankdir=LR;\n",
+		"label=\"r\";",
+		"label=\"s\";",
+		`[label="Release \"one\"", URL="https://github.com/o/r/issues/1", tooltip="Open o/r#1", target="_top"]`,
+		`fillcolor="#222222", fontcolor="#ffffff", class="abandoned"`,
+		`fillcolor="#e5b104", class="active"`,
+		`style="rounded,filled,dashed", class="incomplete"`,
+		`[label="blocks"];`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if n := strings.Count(out, " -> "); n != 4 {
+		t.Errorf("expected 4 edges, got %d in:\n%s", n, out)
+	}
+}
+
+func TestRenderFilter(t *testing.T) {
+	tg := renderFixture()
+
+	for name, render := range map[string]func(*bytes.Buffer, ...RenderOption) error{
+		"mermaid":  func(b *bytes.Buffer, opts ...RenderOption) error { return tg.ToMermaid(b, opts...) },
+		"dot":      func(b *bytes.Buffer, opts ...RenderOption) error { return tg.ToDot(b, opts...) },
+		"plantuml": func(b *bytes.Buffer, opts ...RenderOption) error { return tg.ToPlantUML(b, opts...) },
+		"d2":       func(b *bytes.Buffer, opts ...RenderOption) error { return tg.ToD2(b, opts...) },
+	} {
+		var buf bytes.Buffer
+		if err := render(&buf, WithFilter(HideClosed)); err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(buf.String(), "Feature") {
+			t.Errorf("%s: expected closed tasks to be hidden:\n%s", name, buf.String())
+		}
+		if !strings.Contains(buf.String(), "o/s#4 ...") {
+			t.Errorf("%s: expected incomplete tasks to be shown:\n%s", name, buf.String())
+		}
+	}
+}
+
+func TestIsDark(t *testing.T) {
+	for colour, dark := range map[string]bool{"#222222": true, "#fff": false, "#ccc": false, "#60a1ea": false, "#000": true, "bogus": false} {
+		if isDark(colour) != dark {
+			t.Errorf("isDark(%q) != %v", colour, dark)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package taskgraph
+
+import (
+	"strings"
+)
+
+// Edge kinds.
+const (
+	// EdgeTasklist links an issue to an item of its tasklist.
+	EdgeTasklist = "tasklist"
+	// EdgeBlocks links an issue to one that cannot start until it is done,
+	// see BlockedByKey.
+	EdgeBlocks = "blocks"
+)
+
+// BlockedByKey is the front-matter key under which an issue lists the issues
+// that block it, e.g. "blocked_by: #12, other/repo#3".
+const BlockedByKey = "blocked_by"
+
+// blockedBy lists the issues that block a task, as given in the front-matter
+// of its body. References without a repo are to the repo of the task.
+func blockedBy(t *Task) []*IssueRef {
+	var refs []*IssueRef
+	for _, s := range strings.Split(strings.Trim(frontMatter(t.Body)[BlockedByKey], "[]"), ",") {
+		r, err := ParseIssueRef(strings.Trim(strings.TrimSpace(s), `"'`))
+		if err != nil {
+			continue
+		}
+		if len(r.Owner) == 0 {
+			r.Owner, r.Repo = t.Owner, t.Repo
+		}
+		refs = append(refs, r)
+	}
+	return refs
+}
+
+// Edge describes the link from a task to one of its subtasks.
+type Edge struct {
+	From    string
+	To      string
+	Kind    string
+	Checked bool // whether the item is ticked off in the parent's tasklist
+}
+
+type edgeKey struct {
+	from string
+	to   string
+}
+
+// AddEdge links two tasks, or updates the attributes of an existing link.
+// The edges from a task are kept in the order in which they were first added.
+func (tg *TaskGraph) AddEdge(e Edge) {
+	tg.init()
+	if len(e.Kind) == 0 {
+		e.Kind = EdgeTasklist
+	}
+	k := edgeKey{e.From, e.To}
+	if !tg.HasEdge(e.From, e.To) {
+		tg.Edges[e.From] = append(tg.Edges[e.From], e.To)
+	}
+	tg.attrs[k] = e
+}
+
+// HasEdge reports whether the first task links to the second.
+func (tg *TaskGraph) HasEdge(from, to string) bool {
+	for _, dst := range tg.Edges[from] {
+		if dst == to {
+			return true
+		}
+	}
+	return false
+}
+
+// tasklist lists the items of the tasklists of a task, leaving out its other
+// links, e.g. to the tasks that it blocks.
+func (tg *TaskGraph) tasklist(ref string) []string {
+	var items []string
+	for _, to := range tg.Edges[ref] {
+		if tg.Edge(ref, to).Kind == EdgeTasklist {
+			items = append(items, to)
+		}
+	}
+	return items
+}
+
+// Edge returns the attributes of the link between two tasks. Links that were
+// added directly to Edges are reported as unchecked tasklist edges.
+func (tg *TaskGraph) Edge(from, to string) Edge {
+	if e, ok := tg.attrs[edgeKey{from, to}]; ok {
+		return e
+	}
+	return Edge{From: from, To: to, Kind: EdgeTasklist}
+}
@@ -0,0 +1,106 @@
+package taskgraph_test
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v52/github"
+
+	"go.resystems.io/task-graph/taskgraph"
+)
+
+// memory is an in-memory issue source, keyed by issue reference.
+type memory map[string]*github.Issue
+
+func (m memory) Get(ctx context.Context, owner string, repo string, number int) (*github.Issue, *github.Response, error) {
+	ref := taskgraph.IssueRef{Owner: owner, Repo: repo, Number: number}
+	issue, ok := m[ref.String()]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown issue %v", &ref)
+	}
+	return issue, nil, nil
+}
+
+func issue(title string, state string, body string) *github.Issue {
+	return &github.Issue{Title: &title, State: &state, Body: &body}
+}
+
+func ExampleParseIssueRef() {
+	for _, s := range []string{"resystems-io/task-graph#1", "#2", "task-graph"} {
+		ref, err := taskgraph.ParseIssueRef(s)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		fmt.Printf("owner=%q repo=%q number=%d\n", ref.Owner, ref.Repo, ref.Number)
+	}
+
+	// Output:
+	// owner="resystems-io" repo="task-graph" number=1
+	// owner="" repo="" number=2
+	// bad issue reference: task-graph
+}
+
+func ExampleTaskGraph_AccumulateFrom() {
+	source := memory{
+		"acme/plan#1": issue("Release", "open", "```[tasklist]\n- [ ] #2\n- [x] acme/site#7\n```\n"),
+		"acme/plan#2": issue("Feature", "open", ""),
+		"acme/site#7": issue("Landing page", "closed", ""),
+	}
+
+	tg := taskgraph.New(taskgraph.WithWorkers(2))
+	err := tg.AccumulateFrom(context.Background(), source, &taskgraph.IssueRef{Owner: "acme", Repo: "plan", Number: 1})
+	if err != nil {
+		panic(err)
+	}
+
+	refs := make([]string, 0, len(tg.Refs))
+	for ref := range tg.Refs {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+	for _, ref := range refs {
+		fmt.Printf("%s %q -> %v\n", ref, tg.Refs[ref].Title, tg.Edges[ref])
+	}
+
+	// Output:
+	// acme/plan#1 "Release" -> [acme/plan#2 acme/site#7]
+	// acme/plan#2 "Feature" -> []
+	// acme/site#7 "Landing page" -> []
+}
+
+func ExampleTaskGraph_ToMermaid() {
+	source := memory{
+		"acme/plan#1": issue("Release", "open", "```[tasklist]\n- [x] #2\n```\n"),
+		"acme/plan#2": issue("Feature", "closed", ""),
+	}
+
+	tg := taskgraph.New()
+	err := tg.AccumulateFrom(context.Background(), source, &taskgraph.IssueRef{Owner: "acme", Repo: "plan", Number: 1})
+	if err != nil {
+		panic(err)
+	}
+
+	var b strings.Builder
+	err = tg.ToMermaid(&b, taskgraph.WithDirection("LR"))
+	if err != nil {
+		panic(err)
+	}
+
+	// the nodes, their tasklist edge and the status class of the closed task,
+	// leaving out the theme and the subgraphs
+	for _, line := range strings.Split(b.String(), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "tg_acme_plan_") || strings.HasPrefix(line, "class tg_acme_plan_") {
+			fmt.Println(line)
+		}
+	}
+
+	// Output:
+	// tg_acme_plan_1["Release"]
+	// tg_acme_plan_2["Feature"]
+	// tg_acme_plan_1 --> tg_acme_plan_2
+	// class tg_acme_plan_2 closed;
+}
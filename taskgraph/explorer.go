@@ -0,0 +1,135 @@
+package taskgraph
+
+import (
+	_ "embed"
+	"html/template"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+//go:embed explorer.html
+var explorerHTML string
+
+var explorerTemplate = template.Must(template.New("explorer").Parse(explorerHTML))
+
+// excerptLength is the number of characters of a task's body that are shown
+// in the explorer's tooltips.
+const excerptLength = 280
+
+// explorerData is embedded as JSON into the explorer page.
+type explorerData struct {
+	Title string            `json:"title"`
+	Dir   string            `json:"dir"`
+	Fills map[string]string `json:"fills"`
+	Roots []string          `json:"roots"`
+	Nodes []explorerNode    `json:"nodes"`
+	Edges []explorerEdge    `json:"edges"`
+}
+
+type explorerNode struct {
+	ID         string   `json:"id"`
+	Ref        string   `json:"ref"`
+	Title      string   `json:"title"`
+	URL        string   `json:"url,omitempty"`
+	Repo       string   `json:"repo"`
+	State      string   `json:"state,omitempty"`
+	Class      string   `json:"class,omitempty"`
+	Labels     []string `json:"labels"`
+	Assignees  []string `json:"assignees"`
+	Milestone  string   `json:"milestone,omitempty"`
+	Excerpt    string   `json:"excerpt,omitempty"`
+	Incomplete bool     `json:"incomplete,omitempty"`
+
+	Rollup *explorerRollup `json:"rollup,omitempty"`
+}
+
+type explorerRollup struct {
+	Closed  float64 `json:"closed"`
+	Total   float64 `json:"total"`
+	Percent int     `json:"percent"`
+}
+
+type explorerEdge struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Kind    string `json:"kind"`
+	Checked bool   `json:"checked"`
+}
+
+// excerpt shortens text to at most n characters, with whitespace collapsed.
+func excerpt(text string, n int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= n {
+		return text
+	}
+	runes := []rune(text)
+	return strings.TrimSpace(string(runes[:n])) + "…"
+}
+
+// WriteExplorer writes a single, self-contained HTML page for exploring the
+// graph interactively. The page needs no network access: it embeds the graph
+// as JSON and lays it out itself. It offers search, filters by state, repo,
+// label and assignee, collapsible subtrees, tooltips and pan and zoom.
+func (tg *TaskGraph) WriteExplorer(writer io.Writer, opts ...RenderOption) error {
+
+	cfg, err := newRender(opts...)
+	if err != nil {
+		return err
+	}
+	v, err := tg.view(cfg)
+	if err != nil {
+		return err
+	}
+	fills := make(map[string]string)
+	for _, class := range styledClasses() {
+		fills[class] = cfg.theme.fill(class)
+	}
+
+	data := explorerData{
+		Title: cfg.theme.Title,
+		Dir:   cfg.dir,
+		Fills: fills,
+		Roots: []string{},
+		Nodes: []explorerNode{},
+		Edges: []explorerEdge{},
+	}
+	for _, r := range tg.Roots {
+		if n, ok := v.Nodes[r]; ok {
+			data.Roots = append(data.Roots, n.ID)
+		}
+	}
+	for _, n := range v.exportNodes() {
+		en := explorerNode{
+			ID:         n.ID,
+			Ref:        n.Key,
+			Title:      n.Label,
+			URL:        n.URL,
+			Repo:       n.Ref.Owner + "/" + n.Ref.Repo,
+			Class:      n.Class,
+			Labels:     []string{},
+			Assignees:  []string{},
+			Incomplete: n.Task == nil,
+		}
+		if v.Rollups && n.Rollup != nil {
+			en.Rollup = &explorerRollup{n.Rollup.Closed, n.Rollup.Total, n.Rollup.Percent()}
+		}
+		if t := n.Task; t != nil {
+			en.State = t.State
+			en.Milestone = t.Milestone
+			en.Excerpt = excerpt(t.Body, excerptLength)
+			if t.Labels != nil {
+				en.Labels = t.Labels
+			}
+			if t.Assignees != nil {
+				en.Assignees = t.Assignees
+			}
+		}
+		data.Nodes = append(data.Nodes, en)
+	}
+	for _, e := range v.Edges {
+		data.Edges = append(data.Edges, explorerEdge{e.From.ID, e.To.ID, e.Kind, e.Checked})
+	}
+
+	return explorerTemplate.Execute(writer, data)
+}
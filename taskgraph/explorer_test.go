@@ -0,0 +1,61 @@
+package taskgraph
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteExplorer(t *testing.T) {
+	tg := New()
+	addTask(tg, "o/r#1", `Release "one"`, StateOpen)
+	addTask(tg, "o/r#2", "Feature", StateClosed).StateReason = "not_planned"
+	addTask(tg, "o/s#3", "Docs", StateOpen).Labels = []string{"in progress"}
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/r#2"})
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/s#3"})
+	tg.AddEdge(Edge{From: "o/s#3", To: "o/s#4"})
+	tg.AddEdge(Edge{From: "o/r#2", To: "o/s#3", Kind: EdgeBlocks})
+	tg.Incomplete["o/s#4"] = &IssueRef{"o", "s", 4}
+	tg.Roots = []string{"o/r#1"}
+	tg.Refs["o/r#1"].Body = "Ship it </script><script>alert(1)</script>\n\n- [ ] o/r#2"
+
+	var buf bytes.Buffer
+	if err := tg.WriteExplorer(&buf, WithFilter(HideClosed)); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`"roots":["tg_o_r_1"]`,
+		`"ref":"o/r#1"`,
+		`"excerpt":"Ship it \u003c/script\u003e\u003cscript\u003ealert(1)\u003c/script\u003e - [ ] o/r#2"`,
+		`"labels":["in progress"]`,
+		`"incomplete":true`,
+		`"from":"tg_o_s_3","to":"tg_o_s_4","kind":"tasklist","checked":false`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output", want)
+		}
+	}
+	if strings.Contains(out, `"ref":"o/r#2"`) {
+		t.Errorf("expected closed tasks to be hidden")
+	}
+	if strings.Contains(out, "alert(1)</script>") {
+		t.Errorf("expected the body to be escaped")
+	}
+	if strings.Contains(out, "https://cdn") {
+		t.Errorf("expected no network dependencies")
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	for text, want := range map[string]string{
+		"short":              "short",
+		"  a\n\n b  ":        "a b",
+		"one two three four": "one two…",
+	} {
+		n := 8
+		if got := excerpt(text, n); got != want {
+			t.Errorf("excerpt(%q, %d) = %q, expected %q", text, n, got, want)
+		}
+	}
+}
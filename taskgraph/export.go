@@ -0,0 +1,90 @@
+package taskgraph
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// exportAttr is a node or edge attribute written by the graph exchange
+// formats, GraphML and GEXF.
+type exportAttr[T any] struct {
+	Name  string
+	Type  string         // "string", "int", "double" or "boolean"
+	value func(T) string // "" if the attribute is unset
+}
+
+// exportTime formats a time, leaving zero times unset.
+func exportTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// taskAttr reads an attribute of a fetched task, incomplete nodes have none.
+func taskAttr(value func(*Task) string) func(*viewNode) string {
+	return func(n *viewNode) string {
+		if n.Task == nil {
+			return ""
+		}
+		return value(n.Task)
+	}
+}
+
+// rollupAttr reads an attribute of the rollup of a node, nodes without
+// descendants have none.
+func rollupAttr(value func(*Rollup) string) func(*viewNode) string {
+	return func(n *viewNode) string {
+		if n.Rollup == nil {
+			return ""
+		}
+		return value(n.Rollup)
+	}
+}
+
+var exportNodeAttrs = []exportAttr[*viewNode]{
+	{"title", "string", func(n *viewNode) string {
+		if n.Task != nil {
+			return n.Task.Title
+		}
+		return n.Label
+	}},
+	{"url", "string", func(n *viewNode) string { return n.URL }},
+	{"owner", "string", func(n *viewNode) string { return n.Ref.Owner }},
+	{"repo", "string", func(n *viewNode) string { return n.Ref.Repo }},
+	{"number", "int", func(n *viewNode) string { return strconv.Itoa(n.Ref.Number) }},
+	{"kind", "string", taskAttr(func(t *Task) string { return t.Kind })},
+	{"state", "string", taskAttr(func(t *Task) string { return t.State })},
+	{"state_reason", "string", taskAttr(func(t *Task) string { return t.StateReason })},
+	{"status", "string", func(n *viewNode) string { return n.Class }},
+	{"labels", "string", taskAttr(func(t *Task) string { return strings.Join(t.Labels, ",") })},
+	{"assignees", "string", taskAttr(func(t *Task) string { return strings.Join(t.Assignees, ",") })},
+	{"milestone", "string", taskAttr(func(t *Task) string { return t.Milestone })},
+	{"created", "string", taskAttr(func(t *Task) string { return exportTime(t.Created) })},
+	{"closed", "string", taskAttr(func(t *Task) string { return exportTime(t.Closed) })},
+	{"rollup_closed", "double", rollupAttr(func(r *Rollup) string { return strconv.FormatFloat(r.Closed, 'f', -1, 64) })},
+	{"rollup_total", "double", rollupAttr(func(r *Rollup) string { return strconv.FormatFloat(r.Total, 'f', -1, 64) })},
+	{"rollup_percent", "int", rollupAttr(func(r *Rollup) string { return strconv.Itoa(r.Percent()) })},
+}
+
+var exportEdgeAttrs = []exportAttr[viewEdge]{
+	{"kind", "string", func(e viewEdge) string { return e.Kind }},
+	{"checked", "boolean", func(e viewEdge) string { return strconv.FormatBool(e.Checked) }},
+}
+
+// exportNodes lists the visible nodes, those outside of any group first, and
+// then in group order.
+func (v *view) exportNodes() []*viewNode {
+	nodes := make([]*viewNode, 0, len(v.Nodes))
+	nodes = append(nodes, v.Loose...)
+	var walk func(groups []*viewGroup)
+	walk = func(groups []*viewGroup) {
+		for _, g := range groups {
+			nodes = append(nodes, g.Nodes...)
+			walk(g.Groups)
+		}
+	}
+	walk(v.Groups)
+	return nodes
+}
@@ -0,0 +1,91 @@
+package taskgraph
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func exportFixture() *TaskGraph {
+	tg := New()
+	addTask(tg, "o/r#1", `Release "one"`, StateOpen)
+	t := addTask(tg, "o/r#2", "Feature", StateClosed)
+	t.StateReason = "not_planned"
+	t.Labels = []string{"bug", "ui"}
+	t.Assignees = []string{"alice"}
+	t.Created = time.Date(2023, 5, 1, 9, 0, 0, 0, time.UTC)
+	t.Closed = time.Date(2023, 5, 3, 17, 30, 0, 0, time.UTC)
+	addTask(tg, "o/s#3", "Docs", StateOpen)
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/r#2", Checked: true})
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/s#3"})
+	tg.AddEdge(Edge{From: "o/s#3", To: "o/s#4"})
+	tg.AddEdge(Edge{From: "o/r#2", To: "o/s#3", Kind: EdgeBlocks})
+	tg.Incomplete["o/s#4"] = &IssueRef{"o", "s", 4}
+	return tg
+}
+
+func TestWriteGraphML(t *testing.T) {
+	tg := exportFixture()
+
+	var buf bytes.Buffer
+	if err := tg.WriteGraphML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var doc graphml
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("bad XML: %v\n%s", err, buf.String())
+	}
+	if len(doc.Graph.Nodes) != 4 || len(doc.Graph.Edges) != 4 {
+		t.Fatalf("expected 4 nodes and 4 edges, got %d and %d", len(doc.Graph.Nodes), len(doc.Graph.Edges))
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		`<key id="n_labels" for="node" attr.name="labels" attr.type="string"></key>`,
+		`<key id="e_checked" for="edge" attr.name="checked" attr.type="boolean"></key>`,
+		`<node id="o/r#2">`,
+		`<data key="n_labels">bug,ui</data>`,
+		`<data key="n_assignees">alice</data>`,
+		`<data key="n_created">2023-05-01T09:00:00Z</data>`,
+		`<data key="n_closed">2023-05-03T17:30:00Z</data>`,
+		`<edge id="e0" source="o/r#1" target="o/r#2">`,
+		`<data key="e_checked">true</data>`,
+		`<data key="e_kind">blocks</data>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteGEXF(t *testing.T) {
+	tg := exportFixture()
+
+	var buf bytes.Buffer
+	if err := tg.WriteGEXF(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var doc gexf
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("bad XML: %v\n%s", err, buf.String())
+	}
+	if len(doc.Graph.Nodes) != 4 || len(doc.Graph.Edges) != 4 {
+		t.Fatalf("expected 4 nodes and 4 edges, got %d and %d", len(doc.Graph.Nodes), len(doc.Graph.Edges))
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		`<attribute id="number" title="number" type="integer"></attribute>`,
+		`<node id="o/r#2" label="Feature">`,
+		`<attvalue for="state" value="closed"></attvalue>`,
+		`<attvalue for="labels" value="bug,ui"></attvalue>`,
+		`<node id="o/s#4" label="o/s#4 ...">`,
+		`<attvalue for="checked" value="true"></attvalue>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package taskgraph
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+)
+
+// The project fields, and front-matter keys, that may hold the start and due
+// dates of a task, unless set WithDateFields. They are matched case
+// insensitively, and the first present wins.
+var (
+	DefaultStartFields = []string{"Start date", "Start"}
+	DefaultDueFields   = []string{"Due date", "Due", "Target date", "End date"}
+)
+
+// WithDateFields sets the project fields, or front-matter keys, that hold the
+// start and due dates of tasks, in place of DefaultStartFields and
+// DefaultDueFields.
+func WithDateFields(start, due []string) RenderOption {
+	return func(r *render) {
+		r.startFields = start
+		r.dueFields = due
+	}
+}
+
+// frontMatter reads the simple "key: value" pairs between the "---" lines
+// that may open the body of a task.
+func frontMatter(body string) map[string]string {
+	s := bufio.NewScanner(strings.NewReader(body))
+	if !s.Scan() || strings.TrimSpace(s.Text()) != "---" {
+		return nil
+	}
+	fm := make(map[string]string)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "---" {
+			return fm
+		}
+		if k, v, ok := strings.Cut(line, ":"); ok {
+			fm[strings.ToLower(strings.TrimSpace(k))] = strings.Trim(strings.TrimSpace(v), `"'`)
+		}
+	}
+	return nil // never closed, so not front-matter
+}
+
+// parseDate reads a date, or the date of a timestamp.
+func parseDate(s string) (time.Time, bool) {
+	if len(s) >= len(time.DateOnly) {
+		if t, err := time.Parse(time.DateOnly, s[:len(time.DateOnly)]); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// taskDate finds a date of a task, first in its project fields and then in
+// the front-matter of its body.
+func taskDate(t *Task, fm map[string]string, names []string) (time.Time, bool) {
+	return taskField(t, fm, names, parseDate)
+}
+
+// taskField finds the first of the named fields of a task that parses, first
+// in its project fields and then in the front-matter of its body.
+func taskField[T any](t *Task, fm map[string]string, names []string, parse func(string) (T, bool)) (T, bool) {
+	for _, name := range names {
+		for _, k := range sortedKeys(t.Fields) {
+			if strings.EqualFold(k, name) {
+				if x, ok := parse(t.Fields[k]); ok {
+					return x, true
+				}
+			}
+		}
+		if x, ok := parse(fm[strings.ToLower(name)]); ok {
+			return x, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// ganttText makes text safe for a Mermaid Gantt task or section name, where
+// a colon, semicolon or # would end the name. The # and the characters that
+// HTML reads as markup are written as Mermaid entities, e.g. #35;, which
+// Mermaid decodes when drawing, so that the chart can be inlined into a page.
+func ganttText(text string) string {
+	r := strings.NewReplacer(":", " -", ";", ",", "\n", " ", "\r", "",
+		"#", "#35;", "&", "#amp;", "<", "#lt;", ">", "#gt;")
+	return strings.TrimSpace(r.Replace(text))
+}
+
+func ganttDate(t time.Time) string {
+	return t.UTC().Format(time.DateOnly)
+}
+
+// ToGantt renders the graph as a Mermaid Gantt chart.
+//
+// Each task with subtasks is an epic, with its own section holding its bar
+// followed by the bars of its subtasks, in tasklist order. A task listed by
+// several epics is drawn in the section of the first. Tasks that were never
+// fetched are left out.
+//
+// A task starts on its start date, from a project field or the front-matter
+// of its body (see WithDateFields), otherwise after the tasks that block it
+// (see EdgeBlocks), otherwise on the day it was created (or fetched). A task
+// ends when it was closed, or otherwise on its due date, the due date of its
+// milestone, or the day it was fetched, though never before it starts. Closed
+// tasks are marked done, active tasks as active, and overdue tasks as
+// critical.
+func (tg *TaskGraph) ToGantt(writer io.Writer, opts ...RenderOption) error {
+
+	cfg, err := newRender(opts...)
+	if err != nil {
+		return err
+	}
+	v, err := tg.view(cfg)
+	if err != nil {
+		return err
+	}
+	w := &errWriter{w: writer}
+
+	// the fetched tasks, their subtasks and parents, and what blocks them
+	children := make(map[*viewNode][]*viewNode)
+	parents := make(map[*viewNode][]*viewNode)
+	blockers := make(map[*viewNode][]*viewNode)
+	for _, e := range v.Edges {
+		switch {
+		case e.From.Task == nil || e.To.Task == nil || e.From == e.To:
+		case e.Kind == EdgeBlocks:
+			blockers[e.To] = append(blockers[e.To], e.From)
+		default:
+			children[e.From] = append(children[e.From], e.To)
+			parents[e.To] = append(parents[e.To], e.From)
+		}
+	}
+
+	// when each task ends, and whether that is when it is due
+	ends := func(t *Task) (time.Time, bool) {
+		end, due := taskDate(t, frontMatter(t.Body), cfg.dueFields)
+		switch {
+		case t.IsClosed() && !t.Closed.IsZero():
+			return t.Closed, due
+		case due:
+			return end, true
+		case !t.MilestoneDue.IsZero():
+			return t.MilestoneDue, true
+		}
+		return t.Fetched, false
+	}
+
+	// sections, in tasklist order from the roots
+	type section struct {
+		epic  *viewNode // nil for tasks outside of any epic
+		tasks []*viewNode
+	}
+	var sections []*section
+	loose := &section{}
+	placed := make(map[*viewNode]bool)
+	var walk func(n, from *viewNode)
+	walk = func(n, from *viewNode) {
+		if placed[n] {
+			return
+		}
+		placed[n] = true
+		if len(children[n]) == 0 {
+			if from == nil {
+				loose.tasks = append(loose.tasks, n)
+			}
+			return
+		}
+		s := &section{epic: n, tasks: []*viewNode{n}}
+		sections = append(sections, s)
+		for _, c := range children[n] {
+			if !placed[c] && len(children[c]) == 0 {
+				placed[c] = true
+				s.tasks = append(s.tasks, c)
+			}
+		}
+		for _, c := range children[n] {
+			walk(c, n)
+		}
+	}
+	for _, r := range tg.Roots {
+		if n, ok := v.Nodes[r]; ok && n.Task != nil {
+			walk(n, nil)
+		}
+	}
+	for _, n := range v.exportNodes() {
+		if n.Task != nil && len(parents[n]) == 0 {
+			walk(n, nil)
+		}
+	}
+	for _, n := range v.exportNodes() {
+		if n.Task != nil {
+			walk(n, nil) // only on cycles
+		}
+	}
+	if len(loose.tasks) > 0 {
+		sections = append([]*section{loose}, sections...)
+	}
+
+	w.printf("---\ntitle: %s\n---\n", yamlString(cfg.theme.Title))
+	init, err := cfg.theme.mermaidInit()
+	if err != nil {
+		return err
+	}
+	w.printf("%s", init)
+	w.printf(`
+gantt
+	dateFormat YYYY-MM-DD
+	axisFormat %%Y-%%m-%%d
+`)
+	for _, s := range sections {
+		name := "Tasks"
+		if s.epic != nil {
+			name = v.rollupLabel(s.epic, false)
+		}
+		w.printf("\n\tsection %s\n", ganttText(name))
+		for _, n := range s.tasks {
+			t := n.Task
+			fm := frontMatter(t.Body)
+
+			var tags []string
+			switch {
+			case t.IsClosed():
+				tags = append(tags, "done")
+			case n.Class == StatusActive || n.Class == StatusReview:
+				tags = append(tags, "active")
+			}
+
+			start, explicit := taskDate(t, fm, cfg.startFields)
+			when := ganttDate(start)
+			if !explicit && len(blockers[n]) > 0 {
+				// after the last of its blockers ends
+				var after []string
+				start = time.Time{}
+				for _, b := range blockers[n] {
+					after = append(after, b.ID)
+					if end, _ := ends(b.Task); end.After(start) {
+						start = end
+					}
+				}
+				when = "after " + strings.Join(after, " ")
+			} else if !explicit {
+				start = t.Created
+				if start.IsZero() {
+					start = t.Fetched
+				}
+				when = ganttDate(start)
+			}
+
+			end, due := ends(t)
+			if due && !t.IsClosed() && !t.Fetched.IsZero() && end.Before(t.Fetched) {
+				tags = append(tags, "crit")
+			}
+			until := ganttDate(end)
+			if end.IsZero() || ganttDate(end) <= ganttDate(start) {
+				until = "1d"
+			}
+
+			w.printf("\t%s :%s, %s, %s\n", ganttText(n.Label), strings.Join(append(tags, n.ID), ", "), when, until)
+		}
+	}
+	return w.err
+}
@@ -0,0 +1,102 @@
+package taskgraph
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToGantt(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2023, 5, d, 12, 0, 0, 0, time.UTC) }
+
+	tg := New()
+	tg.Roots = []string{"o/r#1"}
+	epic := addTask(tg, "o/r#1", "Release: one", StateOpen)
+	epic.Created, epic.MilestoneDue = day(1), day(30)
+	done := addTask(tg, "o/r#2", "Design", StateClosed)
+	done.Created, done.Closed = day(2), day(5)
+	late := addTask(tg, "o/r#3", "Build", StateOpen)
+	late.Created, late.Fetched = day(3), day(20)
+	late.Fields = map[string]string{"Start date": "2023-05-06", "Due date": "2023-05-15"}
+	late.Labels = []string{"in progress"}
+	sub := addTask(tg, "o/r#4", "Docs", StateOpen)
+	sub.Created, sub.Fetched = day(4), day(20)
+	sub.Body = "---\nstart: 2023-05-16\ndue: \"2023-05-25\"\n---\n\n- [ ] o/r#5"
+	leaf := addTask(tg, "o/r#5", "Guide", StateOpen)
+	leaf.Created, leaf.Fetched = day(7), day(20)
+	notes := addTask(tg, "o/r#6", "Notes", StateOpen)
+	notes.Created, notes.Fetched = day(8), day(20)
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/r#2"})
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/r#3"})
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/r#4"})
+	tg.AddEdge(Edge{From: "o/r#4", To: "o/r#5"})
+	tg.AddEdge(Edge{From: "o/r#3", To: "o/r#5"})
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/r#6"})
+	tg.AddEdge(Edge{From: "o/r#4", To: "o/r#6", Kind: EdgeBlocks})
+
+	var buf bytes.Buffer
+	if err := tg.ToGantt(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `---
+title: Task Graph
+---
+
+gantt
+	dateFormat YYYY-MM-DD
+	axisFormat %Y-%m-%d
+
+	section Release - one
+	Release - one :tg_o_r_1, 2023-05-01, 2023-05-30
+	Design :done, tg_o_r_2, 2023-05-02, 2023-05-05
+	Notes :tg_o_r_6, after tg_o_r_4, 1d
+
+	section Build
+	Build :active, crit, tg_o_r_3, 2023-05-06, 2023-05-15
+	Guide :tg_o_r_5, 2023-05-07, 2023-05-20
+
+	section Docs
+	Docs :tg_o_r_4, 2023-05-16, 2023-05-25
+`
+	if got := buf.String(); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestGanttText(t *testing.T) {
+	tg := New()
+	addTask(tg, "o/r#1", "Fix #12 </pre><script>alert(1)</script> & more", StateOpen)
+
+	var buf bytes.Buffer
+	if err := tg.ToGantt(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "\tFix #35;12 #lt;/pre#gt;#lt;script#gt;alert(1)#lt;/script#gt; #amp; more :tg_o_r_1, "
+	if got := buf.String(); !strings.Contains(got, want) || strings.Contains(got, "<script") {
+		t.Errorf("expected %q in:\n%s", want, got)
+	}
+
+	// references in node labels
+	label, _ := ParseNodeLabel("{{.Ref}}")
+	buf.Reset()
+	if err := tg.ToGantt(&buf, WithNodeLabel(label)); err != nil {
+		t.Fatal(err)
+	}
+	if want := "\to/r#35;1 :tg_o_r_1, "; !strings.Contains(buf.String(), want) {
+		t.Errorf("expected %q in:\n%s", want, buf.String())
+	}
+}
+
+func TestFrontMatter(t *testing.T) {
+	fm := frontMatter("---\nStart: 2023-05-01\ntitle: 'x: y'\n---\nbody")
+	if fm["start"] != "2023-05-01" || fm["title"] != "x: y" {
+		t.Errorf("unexpected front-matter %v", fm)
+	}
+	if fm := frontMatter("---\nstart: 2023-05-01\n"); fm != nil {
+		t.Errorf("expected unterminated front-matter to be ignored, got %v", fm)
+	}
+	if fm := frontMatter("body\n---\nstart: 2023-05-01\n---\n"); fm != nil {
+		t.Errorf("expected front-matter to open the body, got %v", fm)
+	}
+}
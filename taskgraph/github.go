@@ -0,0 +1,167 @@
+package taskgraph
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v52/github"
+)
+
+// GitHubSource fetches issues, along with their ProjectV2 fields, from GitHub.
+type GitHubSource struct {
+	Client *github.Client
+}
+
+// Get fetches an issue via the REST API.
+func (gs *GitHubSource) Get(ctx context.Context, owner string, repo string, number int) (*github.Issue, *github.Response, error) {
+	return gs.Client.Issues.Get(ctx, owner, repo, number)
+}
+
+const githubProjectFieldsQuery = `query($owner: String!, $repo: String!, $number: Int!) {
+  repository(owner: $owner, name: $repo) {
+    issueOrPullRequest(number: $number) {
+      ... on Issue { projectItems(first: 20) { ...items } }
+      ... on PullRequest { projectItems(first: 20) { ...items } }
+    }
+  }
+}
+fragment items on ProjectV2ItemConnection {
+  nodes {
+    fieldValues(first: 50) {
+      nodes {
+        ... on ProjectV2ItemFieldSingleSelectValue { name field { ...named } }
+        ... on ProjectV2ItemFieldTextValue { text field { ...named } }
+        ... on ProjectV2ItemFieldDateValue { date field { ...named } }
+        ... on ProjectV2ItemFieldNumberValue { number field { ...named } }
+        ... on ProjectV2ItemFieldIterationValue { title field { ...named } }
+      }
+    }
+  }
+}
+fragment named on ProjectV2FieldCommon { name }`
+
+type githubFieldValue struct {
+	Name   *string  `json:"name"`
+	Text   *string  `json:"text"`
+	Date   *string  `json:"date"`
+	Number *float64 `json:"number"`
+	Title  *string  `json:"title"`
+	Field  struct {
+		Name string `json:"name"`
+	} `json:"field"`
+}
+
+func (v *githubFieldValue) value() (string, bool) {
+	switch {
+	case v.Name != nil:
+		return *v.Name, true
+	case v.Text != nil:
+		return *v.Text, true
+	case v.Date != nil:
+		return *v.Date, true
+	case v.Number != nil:
+		return strconv.FormatFloat(*v.Number, 'f', -1, 64), true
+	case v.Title != nil:
+		return *v.Title, true
+	}
+	return "", false
+}
+
+type githubProjectFields struct {
+	Data struct {
+		Repository struct {
+			IssueOrPullRequest struct {
+				ProjectItems struct {
+					Nodes []struct {
+						FieldValues struct {
+							Nodes []githubFieldValue `json:"nodes"`
+						} `json:"fieldValues"`
+					} `json:"nodes"`
+				} `json:"projectItems"`
+			} `json:"issueOrPullRequest"`
+		} `json:"repository"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// Fields fetches the ProjectV2 field values of an issue via the GraphQL API.
+// If the issue belongs to several projects, the first value of each field wins.
+func (gs *GitHubSource) Fields(ctx context.Context, owner string, repo string, number int) (map[string]string, error) {
+	body := map[string]any{
+		"query": githubProjectFieldsQuery,
+		"variables": map[string]any{
+			"owner":  owner,
+			"repo":   repo,
+			"number": number,
+		},
+	}
+	req, err := gs.Client.NewRequest("POST", "graphql", body)
+	if err != nil {
+		return nil, err
+	}
+	var out githubProjectFields
+	if _, err := gs.Client.Do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	if len(out.Errors) > 0 {
+		return nil, fmt.Errorf("graphql: %s", out.Errors[0].Message)
+	}
+
+	fields := make(map[string]string)
+	for _, item := range out.Data.Repository.IssueOrPullRequest.ProjectItems.Nodes {
+		for _, fv := range item.FieldValues.Nodes {
+			if v, ok := fv.value(); ok && len(fv.Field.Name) > 0 {
+				if _, seen := fields[fv.Field.Name]; !seen {
+					fields[fv.Field.Name] = v
+				}
+			}
+		}
+	}
+	return fields, nil
+}
+
+// TaskFromGitHub adapts a GitHub issue, or pull request, to a Task.
+func TaskFromGitHub(ref *IssueRef, issue *github.Issue) *Task {
+	t := &Task{
+		IssueRef:    ref,
+		ID:          ref.String(),
+		URL:         issue.GetHTMLURL(),
+		Title:       issue.GetTitle(),
+		Body:        issue.GetBody(),
+		Kind:        KindIssue,
+		State:       strings.ToLower(issue.GetState()),
+		StateReason: strings.ToLower(issue.GetStateReason()),
+		Created:     issue.GetCreatedAt().Time,
+		Updated:     issue.GetUpdatedAt().Time,
+		Closed:      issue.GetClosedAt().Time,
+	}
+	if len(t.URL) == 0 {
+		t.URL = githubURL(ref)
+	}
+	if issue.IsPullRequest() {
+		t.Kind = KindPullRequest
+	}
+	for _, l := range issue.Labels {
+		t.Labels = append(t.Labels, l.GetName())
+	}
+	for _, a := range issue.Assignees {
+		t.Assignees = append(t.Assignees, a.GetLogin())
+	}
+	if len(t.Assignees) == 0 && issue.Assignee != nil {
+		t.Assignees = append(t.Assignees, issue.Assignee.GetLogin())
+	}
+	if m := issue.Milestone; m != nil {
+		t.Milestone = m.GetTitle()
+		t.MilestoneDue = m.GetDueOn().Time
+	}
+	return t
+}
+
+// githubURL is the browser URL of a GitHub issue.
+func githubURL(ref *IssueRef) string {
+	return fmt.Sprintf("https://github.com/%s/%s/issues/%d", ref.Owner, ref.Repo, ref.Number)
+}
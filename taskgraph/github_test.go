@@ -1,8 +1,17 @@
 package taskgraph
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v52/github"
 )
 
 func ExampleRegexp_gitHubIDMatch() {
@@ -122,3 +131,68 @@ func ExampleRegexp_gitHubIssueParts() {
 	// [1] /resystems-io/this/and/that/issues/123 : resystems-io this/and/that 123 :: resystems-io this/and/that 123
 	// [2] resystems-io/this/and/that/issues/123 : - - 0 :: - - 0
 }
+
+func ExampleTaskFromGitHub() {
+	closed := github.Timestamp{Time: time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)}
+	due := github.Timestamp{Time: time.Date(2023, 6, 30, 0, 0, 0, 0, time.UTC)}
+	issue := &github.Issue{
+		Title:       github.String("Example Feature"),
+		State:       github.String("closed"),
+		StateReason: github.String("not_planned"),
+		HTMLURL:     github.String("https://github.com/resystems-io/task-graph/issues/2"),
+		ClosedAt:    &closed,
+		Labels:      []*github.Label{{Name: github.String("bug")}, {Name: github.String("ui")}},
+		Assignees:   []*github.User{{Login: github.String("octocat")}},
+		Milestone:   &github.Milestone{Title: github.String("v1"), DueOn: &due},
+	}
+
+	t := TaskFromGitHub(&IssueRef{"resystems-io", "task-graph", 2}, issue)
+	fmt.Println(t.ID, t.Kind, t.URL)
+	fmt.Println(t.Title, t.State, t.StateReason, t.IsClosed(), t.Closed.Format(time.DateOnly))
+	fmt.Println(t.Labels, t.Assignees, t.Milestone, t.MilestoneDue.Format(time.DateOnly))
+
+	// Output:
+	// resystems-io/task-graph#2 issue https://github.com/resystems-io/task-graph/issues/2
+	// Example Feature closed not_planned true 2023-06-01
+	// [bug ui] [octocat] v1 2023-06-30
+}
+
+func TestGitHubSourceFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Variables map[string]any `json:"variables"`
+		}
+		if r.URL.Path != "/graphql" || json.NewDecoder(r.Body).Decode(&req) != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		if req.Variables["owner"] != "o" || req.Variables["repo"] != "r" || req.Variables["number"] != float64(7) {
+			t.Errorf("unexpected variables %v", req.Variables)
+		}
+		fmt.Fprint(w, `{"data": {"repository": {"issueOrPullRequest": {"projectItems": {"nodes": [
+			{"fieldValues": {"nodes": [
+				{},
+				{"name": "In Progress", "field": {"name": "Status"}},
+				{"number": 3, "field": {"name": "Estimate"}},
+				{"date": "2023-07-01", "field": {"name": "Due"}}
+			]}},
+			{"fieldValues": {"nodes": [
+				{"name": "Done", "field": {"name": "Status"}}
+			]}}
+		]}}}}}`)
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	gs := &GitHubSource{Client: client}
+
+	fields, err := gs.Fields(context.Background(), "o", "r", 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string]string{"Status": "In Progress", "Estimate": "3", "Due": "2023-07-01"}
+	if fmt.Sprint(fields) != fmt.Sprint(expect) {
+		t.Errorf("expected %v, got %v", expect, fields)
+	}
+}
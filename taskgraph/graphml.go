@@ -0,0 +1,103 @@
+package taskgraph
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+type graphml struct {
+	XMLName xml.Name     `xml:"http://graphml.graphdrawing.org/xmlns graphml"`
+	Keys    []graphmlKey `xml:"key"`
+	Graph   graphmlGraph `xml:"graph"`
+}
+
+type graphmlKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphmlGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphmlNode `xml:"node"`
+	Edges       []graphmlEdge `xml:"edge"`
+}
+
+type graphmlNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphmlData `xml:"data"`
+}
+
+type graphmlEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphmlData `xml:"data"`
+}
+
+type graphmlData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+func graphmlAttrs[T any](attrs []exportAttr[T], prefix string, x T) []graphmlData {
+	var data []graphmlData
+	for _, a := range attrs {
+		if v := a.value(x); len(v) > 0 {
+			data = append(data, graphmlData{prefix + a.Name, v})
+		}
+	}
+	return data
+}
+
+// WriteGraphML exports the graph as GraphML, e.g. for yEd. Nodes are
+// identified by their task reference, and carry the task's attributes.
+func (tg *TaskGraph) WriteGraphML(writer io.Writer, opts ...RenderOption) error {
+
+	cfg, err := newRender(opts...)
+	if err != nil {
+		return err
+	}
+	v, err := tg.view(cfg)
+	if err != nil {
+		return err
+	}
+
+	doc := graphml{Graph: graphmlGraph{ID: "tasks", EdgeDefault: "directed"}}
+	for _, a := range exportNodeAttrs {
+		doc.Keys = append(doc.Keys, graphmlKey{"n_" + a.Name, "node", a.Name, a.Type})
+	}
+	for _, a := range exportEdgeAttrs {
+		doc.Keys = append(doc.Keys, graphmlKey{"e_" + a.Name, "edge", a.Name, a.Type})
+	}
+	for _, n := range v.exportNodes() {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphmlNode{n.Key, graphmlAttrs(exportNodeAttrs, "n_", n)})
+	}
+	for i, e := range v.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphmlEdge{
+			ID:     "e" + strconv.Itoa(i),
+			Source: e.From.Key,
+			Target: e.To.Key,
+			Data:   graphmlAttrs(exportEdgeAttrs, "e_", e),
+		})
+	}
+
+	return writeXML(writer, doc)
+}
+
+// writeXML writes an indented XML document.
+func writeXML(writer io.Writer, doc any) error {
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(writer)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(writer, "\n")
+	return err
+}
@@ -0,0 +1,131 @@
+package taskgraph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Groupings of tasks, see Grouping.
+const (
+	GroupRepo      = "repo"       // by repository, the default
+	GroupOwner     = "owner"      // by owner, e.g. organisation
+	GroupOwnerRepo = "owner/repo" // by repository, nested within owners
+	GroupMilestone = "milestone"  // by milestone
+	GroupAssignee  = "assignee"   // by first assignee
+	GroupLabel     = "label"      // by the first label with a prefix
+	GroupField     = "field"      // by the value of a project field
+	GroupNone      = "none"       // no grouping at all
+)
+
+// Grouping chooses how tasks are grouped, e.g. into subgraphs or clusters.
+type Grouping struct {
+	By    string // one of the Group... constants
+	Value string // the label prefix of GroupLabel, or field of GroupField
+}
+
+// String formats the grouping as ParseGrouping reads it.
+func (g Grouping) String() string {
+	if g.By == GroupLabel || g.By == GroupField {
+		return g.By + ":" + g.Value
+	}
+	return g.By
+}
+
+// ParseGrouping reads a grouping: "repo", "owner", "owner/repo", "milestone",
+// "assignee", "label:<prefix>", "field:<name>" or "none".
+func ParseGrouping(s string) (Grouping, error) {
+	by, value, _ := strings.Cut(s, ":")
+	g := Grouping{By: by, Value: value}
+	return g, g.validate()
+}
+
+func (g Grouping) validate() error {
+	switch g.By {
+	case GroupRepo, GroupOwner, GroupOwnerRepo, GroupMilestone, GroupAssignee, GroupNone:
+		if len(g.Value) > 0 {
+			return fmt.Errorf("bad grouping: %s takes no value", g.By)
+		}
+	case GroupLabel:
+	case GroupField:
+		if len(g.Value) == 0 {
+			return fmt.Errorf("bad grouping: field needs a name, e.g. field:Team")
+		}
+	default:
+		return fmt.Errorf("bad grouping: %q", g.String())
+	}
+	return nil
+}
+
+// WithGrouping sets how tasks are grouped, by repo by default. Tasks that
+// were never fetched are left outside of any group, unless grouped by owner
+// or repo.
+func WithGrouping(g Grouping) RenderOption {
+	return func(r *render) {
+		r.group = g
+	}
+}
+
+// groupKey places a node in a group, by a key unique to the group and the
+// name it is shown with.
+type groupKey struct {
+	key  string
+	name string
+}
+
+// groupKeys finds the nested groups of a node, outermost first.
+func (tg *TaskGraph) groupKeys(n *viewNode, g Grouping, shared map[string]bool) []groupKey {
+	owner, repo := n.Ref.Owner, n.Ref.Owner+"/"+n.Ref.Repo
+	switch g.By {
+	case GroupNone:
+		return nil
+	case GroupOwner:
+		return []groupKey{{owner, owner}}
+	case GroupOwnerRepo:
+		return []groupKey{{owner, owner}, {n.Ref.Repo, n.Ref.Repo}}
+	case GroupRepo:
+		// repos are named alone, unless several owners have one so named
+		if shared[n.Ref.Repo] {
+			return []groupKey{{repo, repo}}
+		}
+		return []groupKey{{repo, n.Ref.Repo}}
+	}
+
+	// the other groupings need the task, so unfetched tasks are left loose
+	t := n.Task
+	if t == nil {
+		return nil
+	}
+	name := ""
+	switch g.By {
+	case GroupMilestone:
+		name = t.Milestone
+		if len(name) == 0 {
+			name = "No milestone"
+		}
+	case GroupAssignee:
+		name = "Unassigned"
+		if len(t.Assignees) > 0 {
+			name = t.Assignees[0]
+		}
+	case GroupLabel:
+		name = "Other"
+		labels := append([]string(nil), t.Labels...)
+		sort.Strings(labels)
+		for _, l := range labels {
+			if v, ok := strings.CutPrefix(l, g.Value); ok && len(v) > 0 {
+				name = v
+				break
+			}
+		}
+	case GroupField:
+		name = "No " + g.Value
+		for _, k := range sortedKeys(t.Fields) {
+			if strings.EqualFold(k, g.Value) && len(t.Fields[k]) > 0 {
+				name = t.Fields[k]
+				break
+			}
+		}
+	}
+	return []groupKey{{name, name}}
+}
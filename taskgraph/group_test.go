@@ -0,0 +1,118 @@
+package taskgraph
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// groupFixture has two owners with a repo of the same name.
+func groupFixture() *TaskGraph {
+	tg := New()
+	tg.Roots = []string{"o/r#1"}
+	one := addTask(tg, "o/r#1", "One", StateOpen)
+	one.Milestone, one.Assignees = "v1", []string{"ann", "bob"}
+	one.Labels = []string{"area/ui", "bug"}
+	two := addTask(tg, "p/r#2", "Two", StateOpen)
+	two.Labels = []string{"bug"}
+	two.Fields = map[string]string{"Team": "Core"}
+	tg.AddEdge(Edge{From: "o/r#1", To: "p/r#2"})
+	tg.AddEdge(Edge{From: "p/r#2", To: "p/s#3"})
+	tg.Incomplete["p/s#3"] = &IssueRef{"p", "s", 3}
+	return tg
+}
+
+// groupNames lists the groups of a view, nested in brackets, with their nodes.
+func groupNames(v *view) string {
+	var b strings.Builder
+	for _, n := range v.Loose {
+		b.WriteString(n.Key + " ")
+	}
+	var walk func(groups []*viewGroup)
+	walk = func(groups []*viewGroup) {
+		for _, g := range groups {
+			b.WriteString(g.Name + "[")
+			for _, n := range g.Nodes {
+				b.WriteString(n.Key + " ")
+			}
+			walk(g.Groups)
+			b.WriteString("] ")
+		}
+	}
+	walk(v.Groups)
+	return strings.TrimSpace(b.String())
+}
+
+func TestGrouping(t *testing.T) {
+	for _, tc := range []struct {
+		grouping string
+		want     string
+	}{
+		{"repo", "o/r[o/r#1 ] p/r[p/r#2 ] s[p/s#3 ]"},
+		{"owner", "o[o/r#1 ] p[p/r#2 p/s#3 ]"},
+		{"owner/repo", "o[r[o/r#1 ] ] p[r[p/r#2 ] s[p/s#3 ] ]"},
+		{"milestone", "p/s#3 No milestone[p/r#2 ] v1[o/r#1 ]"},
+		{"assignee", "p/s#3 Unassigned[p/r#2 ] ann[o/r#1 ]"},
+		{"label:area/", "p/s#3 Other[p/r#2 ] ui[o/r#1 ]"},
+		{"field:team", "p/s#3 Core[p/r#2 ] No team[o/r#1 ]"},
+		{"none", "o/r#1 p/r#2 p/s#3"},
+	} {
+		g, err := ParseGrouping(tc.grouping)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g.String() != tc.grouping {
+			t.Errorf("expected %s to round trip, got %s", tc.grouping, g)
+		}
+		cfg, err := newRender(WithGrouping(g))
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, _ := groupFixture().view(cfg)
+		if got := groupNames(v); got != tc.want {
+			t.Errorf("%s: expected %q, got %q", tc.grouping, tc.want, got)
+		}
+	}
+
+	for _, bad := range []string{"", "repos", "owner:x", "field", "field:"} {
+		if _, err := ParseGrouping(bad); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestNestedGroupRendering(t *testing.T) {
+	tg := groupFixture()
+	g, _ := ParseGrouping("owner/repo")
+
+	var buf bytes.Buffer
+	if err := tg.ToMermaid(&buf, WithGrouping(g)); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"\n\tsubgraph tg_group_o [\"o\"]\n\n\n\t\tsubgraph tg_group_o_r [\"r\"]\n\n\t\t\ttg_o_r_1[\"One\"]\n",
+		"\n\tsubgraph tg_group_p [\"p\"]\n",
+		"\t\tsubgraph tg_group_p_r [\"r\"]\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected %q in:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := tg.ToD2(&buf, WithGrouping(g)); err != nil {
+		t.Fatal(err)
+	}
+	if want := "tg_group_o.tg_group_o_r.tg_o_r_1 -> tg_group_p.tg_group_p_r.tg_p_r_2\n"; !strings.Contains(buf.String(), want) {
+		t.Errorf("expected %q in:\n%s", want, buf.String())
+	}
+
+	buf.Reset()
+	g, _ = ParseGrouping("none")
+	if err := tg.ToDot(&buf, WithGrouping(g)); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "subgraph") {
+		t.Errorf("expected no clusters in:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,47 @@
+package taskgraph
+
+// addTask adds a task to the graph, parsing its owner/repo#number reference.
+func addTask(tg *TaskGraph, ref string, title string, state string) *Task {
+	is, err := ParseIssueRef(ref)
+	if err != nil {
+		panic(err)
+	}
+	t := &Task{IssueRef: is, ID: ref, Title: title, State: state, URL: githubURL(is)}
+	tg.Refs[ref] = t
+	if _, ok := tg.Edges[ref]; !ok {
+		tg.Edges[ref] = []string{}
+	}
+	return t
+}
+
+// link adds tasklist edges from a task, first adding any task not yet in the
+// graph, nor known to be unfetched, as an open task titled after its
+// reference.
+func link(tg *TaskGraph, from string, to ...string) {
+	for _, ref := range append([]string{from}, to...) {
+		_, fetched := tg.Refs[ref]
+		if _, unfetched := tg.Incomplete[ref]; !fetched && !unfetched {
+			addTask(tg, ref, "Task "+ref, StateOpen)
+		}
+	}
+	for _, ref := range to {
+		tg.AddEdge(Edge{From: from, To: ref})
+	}
+}
+
+// layout lays out a graph with the default options.
+func layout(tg *TaskGraph) *graphLayout {
+	cfg, _ := newRender()
+	v, _ := tg.view(cfg)
+	return v.layout(cfg.dir)
+}
+
+// layoutNodeByRef finds the laid out node of a task, or nil.
+func layoutNodeByRef(gl *graphLayout, ref string) *layoutNode {
+	for _, n := range gl.Nodes {
+		if n.Key == ref {
+			return n
+		}
+	}
+	return nil
+}
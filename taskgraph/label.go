@@ -0,0 +1,126 @@
+package taskgraph
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+)
+
+// NodeLabel is the model that node label templates are executed over, see
+// WithNodeLabel.
+type NodeLabel struct {
+	Ref         string // canonical reference, e.g. owner/repo#123
+	Owner       string
+	Repo        string
+	Number      int
+	Title       string
+	Kind        string // KindIssue or KindPullRequest
+	State       string // StateOpen or StateClosed
+	StateReason string
+	Status      string // status class, or "" if the task has none
+	Labels      []string
+	Assignees   []string
+	Milestone   string
+	Fields      map[string]string
+	URL         string
+}
+
+// labelFuncs are the functions available to node label templates, besides
+// the text/template builtins.
+var labelFuncs = template.FuncMap{
+	"join":  func(sep string, s []string) string { return strings.Join(s, sep) },
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"truncate": func(n int, s string) string {
+		if utf8.RuneCountInString(s) <= n {
+			return s
+		}
+		return string([]rune(s)[:n]) + "…"
+	},
+}
+
+// ParseNodeLabel parses a node label template, e.g.
+//
+//	{{.Ref}} {{.Title}}{{if .Assignees}}\n👤 {{join ", " .Assignees}}{{end}}
+//
+// The template yields plain text, the renderers escape it for their format.
+// Line breaks, whether written as a newline or, outside of actions, as a
+// literal \n, start a new line of the label where the format allows it.
+// Within actions, \n is left to the quoted strings, e.g. {{join "\n" .Labels}}.
+func ParseNodeLabel(text string) (*template.Template, error) {
+	tmpl, err := template.New("node-label").Funcs(labelFuncs).Option("missingkey=zero").Parse(labelBreaks(text))
+	if err != nil {
+		return nil, fmt.Errorf("bad node label: %w", err)
+	}
+	return tmpl, nil
+}
+
+// labelBreaks turns each literal \n in the text of a template into a newline,
+// leaving its actions, and the strings within them, alone.
+func labelBreaks(text string) string {
+	var b strings.Builder
+	for len(text) > 0 {
+		open := strings.Index(text, "{{")
+		if open < 0 {
+			open = len(text)
+		}
+		b.WriteString(strings.ReplaceAll(text[:open], `\n`, "\n"))
+		text = text[open:]
+
+		// copy the action, skipping over any quoted "}}"
+		i, quote := 0, byte(0)
+		for i < len(text) && (quote != 0 || !strings.HasPrefix(text[i:], "}}")) {
+			switch c := text[i]; {
+			case quote == 0 && (c == '"' || c == '`' || c == '\''):
+				quote = c
+			case quote != 0 && c == '\\' && quote != '`':
+				i++ // an escaped character
+			case c == quote:
+				quote = 0
+			}
+			i++
+		}
+		i = min(i+2, len(text))
+		b.WriteString(text[:i])
+		text = text[i:]
+	}
+	return b.String()
+}
+
+// WithNodeLabel labels the nodes of fetched tasks with a template, see
+// ParseNodeLabel, in place of their titles. Tasks that were never fetched are
+// labelled with their reference.
+func WithNodeLabel(tmpl *template.Template) RenderOption {
+	return func(r *render) {
+		r.label = tmpl
+	}
+}
+
+// nodeLabel executes the label template over a task.
+func (r *render) nodeLabel(t *Task) (string, error) {
+	if r.label == nil {
+		return t.Title, nil
+	}
+	model := NodeLabel{
+		Ref:         t.IssueRef.String(),
+		Owner:       t.Owner,
+		Repo:        t.Repo,
+		Number:      t.Number,
+		Title:       t.Title,
+		Kind:        t.Kind,
+		State:       t.State,
+		StateReason: t.StateReason,
+		Status:      r.status.Class(t),
+		Labels:      t.Labels,
+		Assignees:   t.Assignees,
+		Milestone:   t.Milestone,
+		Fields:      t.Fields,
+		URL:         t.URL,
+	}
+	var b strings.Builder
+	if err := r.label.Execute(&b, model); err != nil {
+		return "", fmt.Errorf("labelling %s: %w", model.Ref, err)
+	}
+	return strings.TrimRight(strings.ReplaceAll(b.String(), "\r\n", "\n"), "\n"), nil
+}
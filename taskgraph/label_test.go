@@ -0,0 +1,90 @@
+package taskgraph
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNodeLabel(t *testing.T) {
+	tg := New()
+	task := addTask(tg, "o/r#1", `Fix "quotes" & <tags>`, StateOpen)
+	task.Labels = []string{"bug", "ui"}
+	task.Assignees = []string{"ann"}
+	task.Fields = map[string]string{"Size": "M"}
+	addTask(tg, "o/r#2", "Plain", StateOpen)
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/r#2"})
+	tg.Incomplete["o/r#3"] = &IssueRef{Owner: "o", Repo: "r", Number: 3}
+
+	label, err := ParseNodeLabel(`{{.Ref}} {{.Title}}\n🏷 {{join ", " .Labels}} ({{len .Assignees}}) {{.Fields.Size}}{{.Fields.Nope}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := newRender(WithNodeLabel(label))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := tg.view(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for ref, want := range map[string]string{
+		"o/r#1": "o/r#1 Fix \"quotes\" & <tags>\n🏷 bug, ui (1) M",
+		"o/r#2": "o/r#2 Plain\n🏷  (0) ",
+		"o/r#3": "o/r#3 ...",
+	} {
+		if got := v.Nodes[ref].Label; got != want {
+			t.Errorf("%s: expected label %q, got %q", ref, want, got)
+		}
+	}
+
+	// each format escapes the label, and breaks its lines
+	for _, c := range []struct {
+		name   string
+		render func(*TaskGraph, *bytes.Buffer) error
+		want   string
+	}{
+		{"mermaid", func(tg *TaskGraph, b *bytes.Buffer) error { return tg.ToMermaid(b, WithNodeLabel(label)) },
+			`["o/r#1 Fix &ldquo;quotes&rdquo; &amp; &lt;tags&gt;<br/>🏷 bug, ui (1) M"]`},
+		{"dot", func(tg *TaskGraph, b *bytes.Buffer) error { return tg.ToDot(b, WithNodeLabel(label)) },
+			`label="o/r#1 Fix \"quotes\" & <tags>\n🏷 bug, ui (1) M"`},
+		{"outline", func(tg *TaskGraph, b *bytes.Buffer) error { return tg.ToOutline(b, WithNodeLabel(label)) },
+			`o/r#1 Fix "quotes" & \<tags> 🏷 bug, ui (1) M`},
+	} {
+		var buf bytes.Buffer
+		if err := c.render(tg, &buf); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(buf.String(), c.want) {
+			t.Errorf("%s: expected %q in:\n%s", c.name, c.want, buf.String())
+		}
+	}
+
+	// \n breaks lines in the text, and is left to the strings in actions
+	for tmpl, want := range map[string]string{
+		`{{join "\n" .Labels}}`:              "bug\nui",
+		`{{printf "%q\n" .Title}}\n{{.Ref}}`: "\"Fix \\\"quotes\\\" & <tags>\"\n\no/r#1",
+		"{{`}}\\n`}}\\n{{.Number}}":          "}}\\n\n1",
+	} {
+		label, err := ParseNodeLabel(tmpl)
+		if err != nil {
+			t.Fatalf("%s: %v", tmpl, err)
+		}
+		cfg, _ := newRender(WithNodeLabel(label))
+		if got, _ := cfg.nodeLabel(task); got != want {
+			t.Errorf("%s: expected %q, got %q", tmpl, want, got)
+		}
+	}
+
+	// templates are checked when parsed, and when executed
+	if _, err := ParseNodeLabel("{{.Title"); err == nil {
+		t.Error("expected an error for an unclosed action")
+	}
+	bad, err := ParseNodeLabel("{{.Nope}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tg.ToMermaid(&bytes.Buffer{}, WithNodeLabel(bad)); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
@@ -0,0 +1,549 @@
+package taskgraph
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// The dimensions used by the layout, in points. Text is measured by counting
+// characters, which suits the 12pt Helvetica used by the native renderers.
+const (
+	layoutNodeWidth  = 200.0
+	layoutLineHeight = 16.0
+	layoutPadding    = 10.0
+	layoutNodeGap    = 24.0 // between neighbouring nodes of a layer
+	layoutDummyGap   = 8.0  // between edges passing through a layer
+	layoutLayerGap   = 56.0 // between layers
+	layoutMargin     = 20.0
+	layoutLineChars  = 28
+	layoutMaxLines   = 3
+)
+
+// point is a position within a layout.
+type point struct {
+	X, Y float64
+}
+
+// layoutNode is a positioned node, or a dummy node that carries an edge
+// through a layer.
+type layoutNode struct {
+	*viewNode           // nil for dummy nodes
+	Lines      []string // the label, wrapped
+	X, Y, W, H float64  // top left corner, and size
+	Bar        bool     // whether the rollup is drawn as a bar
+
+	layer  int
+	order  int
+	pos    float64 // centre along the layer
+	across float64 // size along the layer
+	along  float64 // size across the layer
+	up     []*layoutNode
+	down   []*layoutNode
+}
+
+func (n *layoutNode) dummy() bool {
+	return n.viewNode == nil
+}
+
+// rollupBar is the box of the bar along the foot of a node that is filled in
+// proportion to its rollup, and the filled width.
+func (n *layoutNode) rollupBar() (x, y, w, h, filled float64) {
+	x, y = n.X+layoutPadding, n.Y+n.H-layoutPadding+2
+	w, h = n.W-2*layoutPadding, 3
+	if n.Rollup != nil && n.Rollup.Total > 0 {
+		filled = w * math.Min(1, n.Rollup.Closed/n.Rollup.Total)
+	}
+	return x, y, w, h, filled
+}
+
+// layoutEdge is an edge routed through the layout. Points runs from the
+// border of the source node to the border of the target node, via the
+// dummy nodes between them.
+type layoutEdge struct {
+	viewEdge
+	Points []point
+	Back   bool // drawn against the flow, as it closes a cycle
+}
+
+// graphLayout is the layered drawing of a view.
+type graphLayout struct {
+	Dir    string
+	Width  float64
+	Height float64
+	Nodes  []*layoutNode // without dummy nodes
+	Edges  []*layoutEdge
+}
+
+// formatFloat formats a coordinate to two decimal places, dropping any
+// trailing zeros.
+func formatFloat(x float64) string {
+	return strconv.FormatFloat(math.Round(x*100)/100, 'f', -1, 64)
+}
+
+// wrapText breaks text into at most limit lines of about n characters,
+// eliding whatever does not fit. Line breaks in the text are kept.
+func wrapText(text string, n, limit int) []string {
+	var lines []string
+	line := ""
+	for i, para := range strings.Split(text, "\n") {
+		if i > 0 {
+			lines = append(lines, line)
+			line = ""
+		}
+		for _, word := range strings.Fields(para) {
+			for utf8.RuneCountInString(word) > n {
+				runes := []rune(word)
+				if len(line) > 0 {
+					lines = append(lines, line)
+					line = ""
+				}
+				lines = append(lines, string(runes[:n]))
+				word = string(runes[n:])
+			}
+			switch {
+			case len(line) == 0:
+				line = word
+			case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= n:
+				line += " " + word
+			default:
+				lines = append(lines, line)
+				line = word
+			}
+		}
+	}
+	if len(line) > 0 || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+	if len(lines) > limit {
+		lines = lines[:limit]
+		last := []rune(lines[limit-1])
+		if len(last) >= n {
+			last = last[:n-1]
+		}
+		lines[limit-1] = string(last) + "…"
+	}
+	return lines
+}
+
+// dagEdge is an edge of the layout, pointing down the layers.
+type dagEdge struct {
+	from, to *layoutNode
+	edge     *layoutEdge
+	chain    []*layoutNode // from, any dummy nodes, to
+}
+
+// layout arranges the view in layers, in the manner of Sugiyama et al:
+// cycles are broken, nodes are assigned to layers by their longest path from
+// a source, long edges are split by dummy nodes, crossings are reduced with
+// the barycentre heuristic and finally nodes are placed close to their
+// neighbours.
+//
+// Reversed directions are laid out forwards, and then mirrored.
+func (v *view) layout(dir string) *graphLayout {
+	gl := &graphLayout{Dir: "TB"}
+	if horizontal(dir) {
+		gl.Dir = "LR"
+	}
+
+	// size the nodes
+	index := make(map[*viewNode]*layoutNode, len(v.Nodes))
+	for _, vn := range v.exportNodes() {
+		n := &layoutNode{viewNode: vn, Lines: wrapText(vn.Label, layoutLineChars, layoutMaxLines)}
+		if v.Rollups && vn.Rollup != nil {
+			n.Lines = append(n.Lines, vn.Rollup.String())
+			n.Bar = true
+		}
+		n.W = layoutNodeWidth
+		n.H = 2*layoutPadding + float64(len(n.Lines)+1)*layoutLineHeight
+		n.across, n.along = n.W, n.H
+		if horizontal(dir) {
+			n.across, n.along = n.H, n.W
+		}
+		index[vn] = n
+		gl.Nodes = append(gl.Nodes, n)
+	}
+
+	dag := layoutAcyclic(gl, v, index)
+	layers := layoutLayers(gl, dag)
+	layoutOrder(layers)
+	layoutPlace(gl, layers)
+	layoutRoute(gl, dag)
+	if reversed(dir) {
+		layoutMirror(gl)
+	}
+	gl.Dir = dir
+	return gl
+}
+
+// layoutMirror flips the layout along its flow, so that it runs bottom to top
+// or right to left.
+func layoutMirror(gl *graphLayout) {
+	flip := func(p point) point {
+		if gl.Dir == "LR" {
+			return point{gl.Width - p.X, p.Y}
+		}
+		return point{p.X, gl.Height - p.Y}
+	}
+	for _, n := range gl.Nodes {
+		p := flip(point{n.X + n.W, n.Y + n.H}) // the far corner becomes the origin
+		if gl.Dir == "LR" {
+			n.X = p.X
+		} else {
+			n.Y = p.Y
+		}
+	}
+	for _, e := range gl.Edges {
+		for i, p := range e.Points {
+			e.Points[i] = flip(p)
+		}
+	}
+}
+
+// layoutAcyclic breaks any cycles by reversing the edges that close them,
+// as found by a depth first search from the sources.
+func layoutAcyclic(gl *graphLayout, v *view, index map[*viewNode]*layoutNode) []*dagEdge {
+	out := make(map[*layoutNode][]*layoutEdge, len(gl.Nodes))
+	indegree := make(map[*layoutNode]int, len(gl.Nodes))
+	for _, e := range v.Edges {
+		if e.From == e.To {
+			continue
+		}
+		le := &layoutEdge{viewEdge: e}
+		gl.Edges = append(gl.Edges, le)
+		from := index[e.From]
+		out[from] = append(out[from], le)
+		indegree[index[e.To]]++
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[*layoutNode]int, len(gl.Nodes))
+	var dfs func(n *layoutNode)
+	dfs = func(n *layoutNode) {
+		state[n] = visiting
+		for _, e := range out[n] {
+			to := index[e.To]
+			switch state[to] {
+			case visiting:
+				e.Back = true
+			case unvisited:
+				dfs(to)
+			}
+		}
+		state[n] = visited
+	}
+	for _, n := range gl.Nodes {
+		if indegree[n] == 0 && state[n] == unvisited {
+			dfs(n)
+		}
+	}
+	for _, n := range gl.Nodes {
+		if state[n] == unvisited {
+			dfs(n)
+		}
+	}
+
+	dag := make([]*dagEdge, 0, len(gl.Edges))
+	for _, e := range gl.Edges {
+		from, to := index[e.From], index[e.To]
+		if e.Back {
+			from, to = to, from
+		}
+		dag = append(dag, &dagEdge{from: from, to: to, edge: e})
+	}
+	return dag
+}
+
+// layoutLayers assigns each node to a layer by its longest path from a
+// source, and adds dummy nodes to edges that span several layers.
+func layoutLayers(gl *graphLayout, dag []*dagEdge) [][]*layoutNode {
+	out := make(map[*layoutNode][]*dagEdge, len(gl.Nodes))
+	indegree := make(map[*layoutNode]int, len(gl.Nodes))
+	for _, e := range dag {
+		out[e.from] = append(out[e.from], e)
+		indegree[e.to]++
+	}
+
+	// longest path, in topological order
+	var queue []*layoutNode
+	for _, n := range gl.Nodes {
+		if indegree[n] == 0 {
+			queue = append(queue, n)
+		}
+	}
+	var layers [][]*layoutNode
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for len(layers) <= n.layer {
+			layers = append(layers, nil)
+		}
+		n.order = len(layers[n.layer])
+		layers[n.layer] = append(layers[n.layer], n)
+		for _, e := range out[n] {
+			e.to.layer = max(e.to.layer, n.layer+1)
+			indegree[e.to]--
+			if indegree[e.to] == 0 {
+				queue = append(queue, e.to)
+			}
+		}
+	}
+
+	// dummy nodes
+	for _, e := range dag {
+		e.chain = []*layoutNode{e.from}
+		for l := e.from.layer + 1; l < e.to.layer; l++ {
+			d := &layoutNode{layer: l, across: 0, along: 0}
+			d.order = len(layers[l])
+			layers[l] = append(layers[l], d)
+			e.chain = append(e.chain, d)
+		}
+		e.chain = append(e.chain, e.to)
+		for i := 1; i < len(e.chain); i++ {
+			a, b := e.chain[i-1], e.chain[i]
+			a.down = append(a.down, b)
+			b.up = append(b.up, a)
+		}
+	}
+	return layers
+}
+
+// layoutCrossings counts the edge crossings between adjacent layers.
+func layoutCrossings(layers [][]*layoutNode) int {
+	crossings := 0
+	for l := 0; l+1 < len(layers); l++ {
+		type segment struct{ a, b int }
+		var segments []segment
+		for _, n := range layers[l] {
+			for _, d := range n.down {
+				segments = append(segments, segment{n.order, d.order})
+			}
+		}
+		for i := range segments {
+			for j := i + 1; j < len(segments); j++ {
+				s, t := segments[i], segments[j]
+				if (s.a < t.a && s.b > t.b) || (s.a > t.a && s.b < t.b) {
+					crossings++
+				}
+			}
+		}
+	}
+	return crossings
+}
+
+// layoutOrder reduces crossings by sorting each layer by the barycentre of
+// its neighbours, sweeping down and up the layers, and keeps the best order
+// found.
+func layoutOrder(layers [][]*layoutNode) {
+	const sweeps = 12
+
+	snapshot := func() [][]*layoutNode {
+		s := make([][]*layoutNode, len(layers))
+		for l := range layers {
+			s[l] = append([]*layoutNode(nil), layers[l]...)
+		}
+		return s
+	}
+	sortLayer := func(layer []*layoutNode, neighbours func(*layoutNode) []*layoutNode) {
+		centre := make(map[*layoutNode]float64, len(layer))
+		for _, n := range layer {
+			ns := neighbours(n)
+			if len(ns) == 0 {
+				centre[n] = float64(n.order)
+				continue
+			}
+			sum := 0.0
+			for _, m := range ns {
+				sum += float64(m.order)
+			}
+			centre[n] = sum / float64(len(ns))
+		}
+		sort.SliceStable(layer, func(i, j int) bool { return centre[layer[i]] < centre[layer[j]] })
+		for i, n := range layer {
+			n.order = i
+		}
+	}
+
+	best, fewest := snapshot(), layoutCrossings(layers)
+	for i := 0; i < sweeps && fewest > 0; i++ {
+		if i%2 == 0 {
+			for l := 1; l < len(layers); l++ {
+				sortLayer(layers[l], func(n *layoutNode) []*layoutNode { return n.up })
+			}
+		} else {
+			for l := len(layers) - 2; l >= 0; l-- {
+				sortLayer(layers[l], func(n *layoutNode) []*layoutNode { return n.down })
+			}
+		}
+		if c := layoutCrossings(layers); c < fewest {
+			best, fewest = snapshot(), c
+		}
+	}
+	for l := range layers {
+		copy(layers[l], best[l])
+		for i, n := range layers[l] {
+			n.order = i
+		}
+	}
+}
+
+// layoutPlace positions the nodes of each layer close to the average of their
+// neighbours, without overlapping, and then spaces out the layers.
+func layoutPlace(gl *graphLayout, layers [][]*layoutNode) {
+	const sweeps = 8
+
+	separation := func(a, b *layoutNode) float64 {
+		gap := layoutNodeGap
+		if a.dummy() || b.dummy() {
+			gap = layoutDummyGap
+		}
+		return (a.across+b.across)/2 + gap
+	}
+	// place moves the nodes of a layer towards their desired positions. The
+	// average of the leftmost and rightmost feasible placements is feasible.
+	place := func(layer []*layoutNode, desired []float64) {
+		left := make([]float64, len(layer))
+		for i := range layer {
+			left[i] = desired[i]
+			if i > 0 {
+				left[i] = math.Max(left[i], left[i-1]+separation(layer[i-1], layer[i]))
+			}
+		}
+		right := make([]float64, len(layer))
+		for i := len(layer) - 1; i >= 0; i-- {
+			right[i] = desired[i]
+			if i < len(layer)-1 {
+				right[i] = math.Min(right[i], right[i+1]-separation(layer[i], layer[i+1]))
+			}
+		}
+		for i, n := range layer {
+			n.pos = (left[i] + right[i]) / 2
+		}
+	}
+	towards := func(layer []*layoutNode, neighbours func(*layoutNode) []*layoutNode) {
+		desired := make([]float64, len(layer))
+		for i, n := range layer {
+			desired[i] = n.pos
+			if ns := neighbours(n); len(ns) > 0 {
+				sum := 0.0
+				for _, m := range ns {
+					sum += m.pos
+				}
+				desired[i] = sum / float64(len(ns))
+			}
+		}
+		place(layer, desired)
+	}
+
+	for _, layer := range layers {
+		pos := 0.0
+		for i, n := range layer {
+			if i > 0 {
+				pos += separation(layer[i-1], n)
+			}
+			n.pos = pos
+		}
+	}
+	for i := 0; i < sweeps; i++ {
+		if i%2 == 0 {
+			for l := 1; l < len(layers); l++ {
+				towards(layers[l], func(n *layoutNode) []*layoutNode { return n.up })
+			}
+		} else {
+			for l := len(layers) - 2; l >= 0; l-- {
+				towards(layers[l], func(n *layoutNode) []*layoutNode { return n.down })
+			}
+		}
+	}
+
+	// normalise, and space out the layers
+	least := math.Inf(1)
+	for _, layer := range layers {
+		for _, n := range layer {
+			least = math.Min(least, n.pos-n.across/2)
+		}
+	}
+	across, along := 0.0, layoutMargin
+	for _, layer := range layers {
+		thickness := 0.0
+		for _, n := range layer {
+			thickness = math.Max(thickness, n.along)
+		}
+		for _, n := range layer {
+			n.pos += layoutMargin - least
+			across = math.Max(across, n.pos+n.across/2)
+			centre := point{n.pos, along + thickness/2}
+			if gl.Dir == "LR" {
+				centre = point{centre.Y, centre.X}
+			}
+			n.X, n.Y = centre.X-n.W/2, centre.Y-n.H/2
+		}
+		along += thickness + layoutLayerGap
+	}
+	along += layoutMargin - layoutLayerGap
+	across += layoutMargin
+	gl.Width, gl.Height = across, along
+	if gl.Dir == "LR" {
+		gl.Width, gl.Height = along, across
+	}
+	if len(layers) == 0 {
+		gl.Width, gl.Height = 2*layoutMargin, 2*layoutMargin
+	}
+}
+
+// layoutRoute routes each edge from its source to its target, through the
+// positions of its dummy nodes.
+func layoutRoute(gl *graphLayout, dag []*dagEdge) {
+	for _, e := range dag {
+		points := make([]point, 0, len(e.chain))
+		for i, n := range e.chain {
+			cx, cy := n.X+n.W/2, n.Y+n.H/2
+			switch {
+			case i == 0 && gl.Dir == "LR":
+				points = append(points, point{n.X + n.W, cy})
+			case i == 0:
+				points = append(points, point{cx, n.Y + n.H})
+			case i == len(e.chain)-1 && gl.Dir == "LR":
+				points = append(points, point{n.X, cy})
+			case i == len(e.chain)-1:
+				points = append(points, point{cx, n.Y})
+			default:
+				points = append(points, point{cx, cy})
+			}
+		}
+		if e.edge.Back {
+			for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+				points[i], points[j] = points[j], points[i]
+			}
+		}
+		e.edge.Points = points
+	}
+}
+
+// curve smooths the points of an edge into cubic Bézier segments, returned
+// as the start point followed by two control points and an end point per
+// segment. Each segment leaves and enters along the flow of the layout.
+func (e *layoutEdge) curve(dir string) []point {
+	ps := e.Points
+	if len(ps) == 0 {
+		return nil
+	}
+	curve := []point{ps[0]}
+	for i := 1; i < len(ps); i++ {
+		a, b := ps[i-1], ps[i]
+		if horizontal(dir) {
+			mid := (a.X + b.X) / 2
+			curve = append(curve, point{mid, a.Y}, point{mid, b.Y}, b)
+		} else {
+			mid := (a.Y + b.Y) / 2
+			curve = append(curve, point{a.X, mid}, point{b.X, mid}, b)
+		}
+	}
+	return curve
+}
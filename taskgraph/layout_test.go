@@ -0,0 +1,128 @@
+package taskgraph
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLayoutLayers(t *testing.T) {
+	// a diamond, with a shortcut from the top to the bottom
+	tg := New()
+	link(tg, "o/r#1", "o/r#2", "o/r#3")
+	link(tg, "o/r#2", "o/r#4")
+	link(tg, "o/r#3", "o/r#4")
+	link(tg, "o/r#1", "o/r#4")
+	gl := layout(tg)
+
+	layers := map[string]int{"o/r#1": 0, "o/r#2": 1, "o/r#3": 1, "o/r#4": 2}
+	for ref, layer := range layers {
+		if n := layoutNodeByRef(gl, ref); n.layer != layer {
+			t.Errorf("expected %s in layer %d, got %d", ref, layer, n.layer)
+		}
+	}
+
+	// the shortcut passes through a dummy node
+	for _, e := range gl.Edges {
+		want := 2
+		if e.From.Key == "o/r#1" && e.To.Key == "o/r#4" {
+			want = 3
+		}
+		if len(e.Points) != want {
+			t.Errorf("expected %d points from %s to %s, got %v", want, e.From.Key, e.To.Key, e.Points)
+		}
+	}
+
+	// nodes do not overlap, and lie within the drawing
+	for i, a := range gl.Nodes {
+		if a.X < 0 || a.Y < 0 || a.X+a.W > gl.Width || a.Y+a.H > gl.Height {
+			t.Errorf("%s lies outside the drawing", a.Key)
+		}
+		for _, b := range gl.Nodes[i+1:] {
+			if a.X < b.X+b.W && b.X < a.X+a.W && a.Y < b.Y+b.H && b.Y < a.Y+a.H {
+				t.Errorf("%s overlaps %s", a.Key, b.Key)
+			}
+		}
+	}
+}
+
+func TestLayoutCycle(t *testing.T) {
+	tg := New()
+	link(tg, "o/r#1", "o/r#2")
+	link(tg, "o/r#2", "o/r#3")
+	link(tg, "o/r#3", "o/r#1")
+	gl := layout(tg)
+
+	var back []string
+	for _, e := range gl.Edges {
+		if e.Back {
+			back = append(back, e.From.Key+" -> "+e.To.Key)
+			// drawn from its source, against the flow
+			if first, last := e.Points[0], e.Points[len(e.Points)-1]; first.Y < last.Y {
+				t.Errorf("expected the back edge to point upwards: %v", e.Points)
+			}
+		}
+	}
+	if !slices.Equal(back, []string{"o/r#3 -> o/r#1"}) {
+		t.Errorf("expected one back edge, got %v", back)
+	}
+}
+
+func TestLayoutCrossings(t *testing.T) {
+	// without reordering, the edges to 5 and 4 would cross
+	tg := New()
+	link(tg, "o/r#1", "o/r#2", "o/r#3")
+	link(tg, "o/r#2", "o/r#5")
+	link(tg, "o/r#3", "o/r#4")
+	gl := layout(tg)
+	if a, b := layoutNodeByRef(gl, "o/r#4"), layoutNodeByRef(gl, "o/r#5"); a.X < b.X {
+		t.Errorf("expected o/r#5 left of o/r#4, got %v and %v", b.X, a.X)
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	for _, c := range []struct {
+		text string
+		want []string
+	}{
+		{"", []string{""}},
+		{"short title", []string{"short title"}},
+		{"a somewhat longer title", []string{"a somewhat", "longer title"}},
+		{"averyveryverylongword", []string{"averyveryver", "ylongword"}},
+		{"one two three four five six seven eight", []string{"one two", "three four", "five six…"}},
+		{"title\nby someone", []string{"title", "by someone"}},
+	} {
+		if got := wrapText(c.text, 12, 3); !slices.Equal(got, c.want) {
+			t.Errorf("wrapText(%q) = %q, expected %q", c.text, got, c.want)
+		}
+	}
+}
+
+func TestLayoutReversed(t *testing.T) {
+	tg := New()
+	addTask(tg, "o/r#1", "Top", StateOpen)
+	addTask(tg, "o/r#2", "Bottom", StateOpen)
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/r#2"})
+	cfg, _ := newRender()
+	v, _ := tg.view(cfg)
+
+	for dir, before := range map[string]func(a, b *layoutNode) bool{
+		"TB": func(a, b *layoutNode) bool { return a.Y+a.H < b.Y },
+		"BT": func(a, b *layoutNode) bool { return b.Y+b.H < a.Y },
+		"LR": func(a, b *layoutNode) bool { return a.X+a.W < b.X },
+		"RL": func(a, b *layoutNode) bool { return b.X+b.W < a.X },
+	} {
+		gl := v.layout(dir)
+		from, to := layoutNodeByRef(gl, "o/r#1"), layoutNodeByRef(gl, "o/r#2")
+		if !before(from, to) {
+			t.Errorf("%s: expected the parent first, got %v,%v and %v,%v", dir, from.X, from.Y, to.X, to.Y)
+		}
+		if from.X < 0 || from.Y < 0 || to.X < 0 || to.Y < 0 {
+			t.Errorf("%s: nodes lie outside the drawing", dir)
+		}
+		// the edge runs from the parent to the child
+		ps := gl.Edges[0].Points
+		if dx, dy := ps[0].X-(from.X+from.W/2), ps[0].Y-(from.Y+from.H/2); dx*dx+dy*dy > from.W*from.W {
+			t.Errorf("%s: expected the edge to leave the parent, got %v", dir, ps)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package taskgraph
+
+import (
+	htm "html"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// mermaidEscape makes text safe for use in a quoted Mermaid label, breaking
+// lines where the text does.
+func mermaidEscape(text string) string {
+	escaped := htm.EscapeString(text)
+	// not ideal... but mermaid breaks on " or &quot; or &#34;
+	r := strings.NewReplacer(" &#34;", " &ldquo;", "&#34; ", "&rdquo; ", "&#34;", "'", "\n", "<br/>")
+	return r.Replace(escaped)
+}
+
+// ToMermaid renders the graph as a Mermaid flowchart, with a subgraph per
+// group of tasks, by default per repository (see WithGrouping).
+func (tg *TaskGraph) ToMermaid(writer io.Writer, opts ...RenderOption) error {
+
+	cfg, err := newRender(opts...)
+	if err != nil {
+		return err
+	}
+	v, err := tg.view(cfg)
+	if err != nil {
+		return err
+	}
+	w := &errWriter{w: writer}
+
+	// output header
+	init, err := cfg.theme.mermaidInit()
+	if err != nil {
+		return err
+	}
+	w.printf("---\ntitle: %s\n---\n%s\n", yamlString(cfg.theme.Title), init)
+	framed := len(cfg.theme.Frame) > 0
+	if framed {
+		w.printf("flowchart\n\nsubgraph tg_frame [\"%s\"]\n\n\tdirection %s\n", mermaidEscape(cfg.theme.Frame), cfg.dir)
+	} else {
+		w.printf("flowchart %s\n", cfg.dir)
+	}
+
+	// output nodes, within their subgraphs
+	node := func(n *viewNode, indent string) {
+		w.printf("%s%s[\"%s\"]\n", indent, n.ID, mermaidEscape(v.rollupLabel(n, true)))
+		if len(n.URL) > 0 {
+			w.printf("%sclick %s href \"%s\" \"Open %s\"\n", indent, n.ID, n.URL, n.Key)
+		}
+		w.printf("\n")
+	}
+	var group func(g *viewGroup, indent string)
+	group = func(g *viewGroup, indent string) {
+		w.printf("\n%ssubgraph %s [\"%s\"]\n\n", indent, g.ID, mermaidEscape(g.Name))
+		for _, n := range g.Nodes {
+			node(n, indent+"\t")
+		}
+		for _, sub := range g.Groups {
+			group(sub, indent+"\t")
+		}
+		w.printf("\n%send\n", indent)
+	}
+	if len(v.Loose) > 0 {
+		w.printf("\n")
+	}
+	for _, n := range v.Loose {
+		node(n, "\t")
+	}
+	for _, g := range v.Groups {
+		group(g, "\t")
+	}
+
+	// output edges
+	for _, e := range v.Edges {
+		if label := e.Label(); len(label) > 0 {
+			w.printf("\t\t%s -->|\"%s\"| %s\n", e.From.ID, mermaidEscape(label), e.To.ID)
+		} else {
+			w.printf("\t\t%s --> %s\n", e.From.ID, e.To.ID)
+		}
+	}
+
+	// output footer
+	if framed {
+		w.printf("\nend\n")
+	}
+	w.printf("\nclassDef tasks fill:%s\n", cfg.theme.fill(PaletteFrame))
+	w.printf("classDef projects fill:%s\n\n", cfg.theme.fill(PaletteGroup))
+	for _, class := range styledClasses() {
+		fill := cfg.theme.fill(class)
+		w.printf("classDef %s fill:%s", class, fill)
+		if isDark(fill) {
+			w.printf(",color:#fff")
+		}
+		if class == StatusIncomplete {
+			w.printf(",stroke-dasharray:5 5")
+		}
+		w.printf("\n")
+	}
+	if framed {
+		w.printf("\nclass tg_frame tasks;\n")
+	}
+	for _, g := range v.allGroups() {
+		w.printf("\tclass %s projects;\n", g.ID)
+	}
+
+	// output classes
+	for _, n := range v.exportNodes() {
+		if len(n.Class) > 0 {
+			w.printf("\tclass %s %s;\n", n.ID, n.Class)
+		}
+	}
+
+	// output the critical path, outlining its nodes and edges
+	if v.Critical != nil {
+		critical := cfg.theme.fill(PaletteCritical)
+		w.printf("\nclassDef critical stroke:%s,stroke-width:4px\n", critical)
+		for _, n := range v.exportNodes() {
+			if _, ok := v.Critical[n.Key]; ok {
+				w.printf("\tclass %s critical;\n", n.ID)
+			}
+		}
+		var links []string
+		for i, e := range v.Edges {
+			if v.critical(e) {
+				links = append(links, strconv.Itoa(i))
+			}
+		}
+		if len(links) > 0 {
+			w.printf("\tlinkStyle %s stroke:%s,stroke-width:4px;\n", strings.Join(links, ","), critical)
+		}
+	}
+
+	return w.err
+}
@@ -0,0 +1,60 @@
+package taskgraph
+
+import (
+	"log/slog"
+)
+
+// Option configures a TaskGraph.
+type Option func(*TaskGraph)
+
+// New creates an empty task graph configured with the given options.
+func New(opts ...Option) *TaskGraph {
+	tg := &TaskGraph{}
+	tg.init()
+	for _, opt := range opts {
+		opt(tg)
+	}
+	return tg
+}
+
+// WithSkipClosed stops the traversal from following the tasklists of closed
+// issues. The closed issues themselves are still included in the graph.
+func WithSkipClosed(skip bool) Option {
+	return func(tg *TaskGraph) {
+		tg.skip_closed = skip
+	}
+}
+
+// WithWorkers sets the number of concurrent issue fetches. A count of zero or
+// less selects DefaultWorkers.
+func WithWorkers(n int) Option {
+	return func(tg *TaskGraph) {
+		tg.workers = n
+	}
+}
+
+// WithLogger sets the logger used during traversal. A nil logger discards all
+// logging, which is the default.
+func WithLogger(l *slog.Logger) Option {
+	return func(tg *TaskGraph) {
+		tg.log = l
+	}
+}
+
+// WithProgress registers a callback that is invoked each time an issue fetch
+// completes. The callback is invoked from a single goroutine.
+func WithProgress(fn func(Progress)) Option {
+	return func(tg *TaskGraph) {
+		tg.progress = fn
+	}
+}
+
+// WithFields fetches the named custom fields of each task, such as the
+// "Status" of a GitHub ProjectV2, into Task.Fields. Fields are only fetched
+// from sources that implement FieldSource, and cost an additional request per
+// task.
+func WithFields(names ...string) Option {
+	return func(tg *TaskGraph) {
+		tg.fields = names
+	}
+}
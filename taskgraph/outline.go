@@ -0,0 +1,127 @@
+package taskgraph
+
+import (
+	"io"
+	"strings"
+)
+
+// treeItem is a task in the outline of a graph, as found by walking the
+// edges from the roots.
+type treeItem struct {
+	*viewNode
+	Depth     int
+	Seen      bool // a back-reference, to a task already shown in full
+	Collapsed bool // the children are listed, but not shown
+	Children  []*treeItem
+}
+
+// tree walks the edges of the view from the roots, in tasklist order. Each
+// task is shown in full once, where it is first reached, and anywhere else
+// only as a back-reference. Tasks not reachable from the roots, e.g. as their
+// parents are filtered out, follow as further top-level items.
+//
+// If expand is given, only the subtasks of the tasks it accepts are walked,
+// though all of the subtasks are still listed, unexpanded, for counting.
+func (tg *TaskGraph) tree(v *view, expand func(n *viewNode, depth int) bool) []*treeItem {
+	children := make(map[*viewNode][]*viewNode, len(v.Nodes))
+	parents := make(map[*viewNode]int, len(v.Nodes))
+	for _, e := range v.Edges {
+		children[e.From] = append(children[e.From], e.To)
+		parents[e.To]++
+	}
+
+	seen := make(map[*viewNode]bool, len(v.Nodes))
+	listed := make(map[*viewNode]bool, len(v.Nodes)) // under a collapsed task
+	var walk func(n *viewNode, depth int) *treeItem
+	walk = func(n *viewNode, depth int) *treeItem {
+		item := &treeItem{viewNode: n, Depth: depth, Seen: seen[n]}
+		if item.Seen {
+			return item
+		}
+		seen[n] = true
+		if expand != nil && !expand(n, depth) {
+			for _, c := range children[n] {
+				listed[c] = true
+				item.Children = append(item.Children, &treeItem{viewNode: c, Depth: depth + 1, Seen: seen[c]})
+			}
+			item.Collapsed = len(children[n]) > 0
+			return item
+		}
+		for _, c := range children[n] {
+			item.Children = append(item.Children, walk(c, depth+1))
+		}
+		return item
+	}
+
+	var roots []*treeItem
+	for _, r := range tg.Roots {
+		if n, ok := v.Nodes[r]; ok && !seen[n] && !listed[n] {
+			roots = append(roots, walk(n, 0))
+		}
+	}
+	for _, n := range v.exportNodes() {
+		if parents[n] == 0 && !seen[n] && !listed[n] {
+			roots = append(roots, walk(n, 0))
+		}
+	}
+	for _, n := range v.exportNodes() {
+		if !seen[n] && !listed[n] {
+			roots = append(roots, walk(n, 0)) // only on cycles
+		}
+	}
+	return roots
+}
+
+// markdownEscape makes text safe for inline Markdown, within a line.
+func markdownEscape(text string) string {
+	r := strings.NewReplacer(
+		`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`,
+		"<", `\<`, "\n", " ",
+	)
+	return r.Replace(text)
+}
+
+// ToOutline renders the graph as nested Markdown checklists, e.g. for release
+// notes, following the tasklists from the roots in order. Closed tasks are
+// ticked. A task reachable from several parents is listed in full under the
+// first, and elsewhere as a back-reference.
+func (tg *TaskGraph) ToOutline(writer io.Writer, opts ...RenderOption) error {
+
+	cfg, err := newRender(opts...)
+	if err != nil {
+		return err
+	}
+	v, err := tg.view(cfg)
+	if err != nil {
+		return err
+	}
+	w := &errWriter{w: writer}
+
+	var list func(items []*treeItem)
+	list = func(items []*treeItem) {
+		for _, item := range items {
+			tick := " "
+			if item.Task != nil && item.Task.IsClosed() {
+				tick = "x"
+			}
+			ref := markdownEscape(item.Key)
+			if len(item.URL) > 0 {
+				ref = "[" + ref + "](" + item.URL + ")"
+			}
+			w.printf("%s- [%s] %s", strings.Repeat("  ", item.Depth), tick, ref)
+			if item.Task != nil {
+				w.printf(" %s", markdownEscape(v.rollupLabel(item.viewNode, false)))
+			} else {
+				w.printf(" _(not fetched)_")
+			}
+			if item.Seen {
+				w.printf(" _(see above)_")
+			}
+			w.printf("\n")
+			list(item.Children)
+		}
+	}
+	list(tg.tree(v, nil))
+
+	return w.err
+}
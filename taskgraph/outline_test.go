@@ -0,0 +1,38 @@
+package taskgraph
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestToOutline(t *testing.T) {
+	tg := New()
+	addTask(tg, "o/r#1", `Release "one"`, StateOpen)
+	addTask(tg, "o/r#2", "Feature", StateClosed).StateReason = "not_planned"
+	addTask(tg, "o/s#3", "Docs", StateOpen).Labels = []string{"in progress"}
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/r#2"})
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/s#3"})
+	tg.AddEdge(Edge{From: "o/s#3", To: "o/s#4"})
+	tg.AddEdge(Edge{From: "o/r#2", To: "o/s#3", Kind: EdgeBlocks})
+	tg.Incomplete["o/s#4"] = &IssueRef{"o", "s", 4}
+	tg.Roots = []string{"o/r#1"}
+	addTask(tg, "o/r#5", "Notes [draft]", StateOpen)
+	tg.AddEdge(Edge{From: "o/s#3", To: "o/r#5"})
+	tg.AddEdge(Edge{From: "o/r#5", To: "o/s#3"})
+
+	var buf bytes.Buffer
+	if err := tg.ToOutline(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `- [ ] [o/r#1](https://github.com/o/r/issues/1) Release "one"
+  - [x] [o/r#2](https://github.com/o/r/issues/2) Feature
+    - [ ] [o/s#3](https://github.com/o/s/issues/3) Docs
+      - [ ] [o/s#4](https://github.com/o/s/issues/4) _(not fetched)_
+      - [ ] [o/r#5](https://github.com/o/r/issues/5) Notes \[draft\]
+        - [ ] [o/s#3](https://github.com/o/s/issues/3) Docs _(see above)_
+  - [ ] [o/s#3](https://github.com/o/s/issues/3) Docs _(see above)_
+`
+	if got := buf.String(); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
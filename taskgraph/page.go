@@ -0,0 +1,164 @@
+package taskgraph
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// DefaultDPI is the resolution of raster images, unless set WithDPI.
+const DefaultDPI = 96
+
+// pageMargin surrounds the graph when drawn on a page, in points.
+const pageMargin = 36.0
+
+// PageSize is the size of a page in points, 1/72 of an inch. The zero
+// PageSize fits the page to the graph.
+type PageSize struct {
+	Width, Height float64
+}
+
+// Common page sizes, in portrait.
+var (
+	PageA3     = PageSize{841.89, 1190.55}
+	PageA4     = PageSize{595.28, 841.89}
+	PageLetter = PageSize{612, 792}
+	PageLegal  = PageSize{612, 1008}
+)
+
+// Landscape turns the page on its side.
+func (p PageSize) Landscape() PageSize {
+	return PageSize{max(p.Width, p.Height), min(p.Width, p.Height)}
+}
+
+// ParsePageSize reads a page size by name: "fit", "a3", "a4", "letter" or
+// "legal", optionally followed by "-landscape".
+func ParsePageSize(name string) (PageSize, error) {
+	base, landscape := strings.CutSuffix(strings.ToLower(name), "-landscape")
+	var page PageSize
+	switch base {
+	case "fit":
+		return PageSize{}, nil
+	case "a3":
+		page = PageA3
+	case "a4":
+		page = PageA4
+	case "letter":
+		page = PageLetter
+	case "legal":
+		page = PageLegal
+	default:
+		return PageSize{}, fmt.Errorf("bad page size: %s", name)
+	}
+	if landscape {
+		page = page.Landscape()
+	}
+	return page, nil
+}
+
+// sheet is a page, and where the graph is drawn on it. A point (x, y) of the
+// layout is drawn at (X + x*Scale, Y + y*Scale) from the top left of the page.
+// Tiled sheets are clipped to within their margins.
+type sheet struct {
+	Width, Height float64
+	X, Y, Scale   float64
+	Clip          bool
+}
+
+// sheets places the layout on one or more pages.
+func (r *render) sheets(gl *graphLayout) []sheet {
+	page := r.page
+	if page == (PageSize{}) {
+		return []sheet{{Width: gl.Width, Height: gl.Height, Scale: 1}}
+	}
+	areaW, areaH := page.Width-2*pageMargin, page.Height-2*pageMargin
+	if !r.tile || (gl.Width <= areaW && gl.Height <= areaH) {
+		scale := math.Min(1, math.Min(areaW/gl.Width, areaH/gl.Height))
+		return []sheet{{
+			Width:  page.Width,
+			Height: page.Height,
+			X:      (page.Width - gl.Width*scale) / 2,
+			Y:      (page.Height - gl.Height*scale) / 2,
+			Scale:  scale,
+		}}
+	}
+	cols, rows := int(math.Ceil(gl.Width/areaW)), int(math.Ceil(gl.Height/areaH))
+	sheets := make([]sheet, 0, cols*rows)
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			sheets = append(sheets, sheet{
+				Width:  page.Width,
+				Height: page.Height,
+				X:      pageMargin - float64(col)*areaW,
+				Y:      pageMargin - float64(row)*areaH,
+				Scale:  1,
+				Clip:   true,
+			})
+		}
+	}
+	return sheets
+}
+
+// flatten approximates a curve, as returned by layoutEdge.curve, by a line
+// through a number of points per Bézier segment.
+func flatten(curve []point, steps int) []point {
+	if len(curve) == 0 {
+		return nil
+	}
+	line := []point{curve[0]}
+	for i := 1; i+2 < len(curve); i += 3 {
+		p0, p1, p2, p3 := curve[i-1], curve[i], curve[i+1], curve[i+2]
+		for s := 1; s <= steps; s++ {
+			t := float64(s) / float64(steps)
+			u := 1 - t
+			a, b, c, d := u*u*u, 3*u*u*t, 3*u*t*t, t*t*t
+			line = append(line, point{
+				a*p0.X + b*p1.X + c*p2.X + d*p3.X,
+				a*p0.Y + b*p1.Y + c*p2.Y + d*p3.Y,
+			})
+		}
+	}
+	return line
+}
+
+// arrowHead is the triangle drawn at the end of a curve, pointing along the
+// final control point to the end point. The tip is the first point.
+func arrowHead(curve []point) [3]point {
+	const length, width = 8.0, 3.5
+	tip, from := curve[len(curve)-1], curve[len(curve)-2]
+	dx, dy := tip.X-from.X, tip.Y-from.Y
+	d := math.Hypot(dx, dy)
+	if d == 0 {
+		dx, dy, d = 0, 1, 1
+	}
+	dx, dy = dx/d, dy/d
+	base := point{tip.X - dx*length, tip.Y - dy*length}
+	return [3]point{
+		tip,
+		{base.X - dy*width, base.Y + dx*width},
+		{base.X + dy*width, base.Y - dx*width},
+	}
+}
+
+// parseColour reads a #rgb or #rrggbb colour.
+func parseColour(colour string) (r, g, b uint8) {
+	hex := strings.TrimPrefix(colour, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	var rgb uint32
+	fmt.Sscanf(hex, "%06x", &rgb)
+	return uint8(rgb >> 16), uint8(rgb >> 8), uint8(rgb)
+}
+
+// nodeColours are the fill and text colours of a node.
+func nodeColours(n *layoutNode) (fill, text string) {
+	fill, text = "#ffffff", "#000000"
+	if f := n.Fill; len(f) > 0 {
+		fill = f
+		if isDark(f) {
+			text = "#ffffff"
+		}
+	}
+	return fill, text
+}
@@ -0,0 +1,236 @@
+package taskgraph
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// pdfWinAnsi maps the characters outside of Latin-1 that the standard PDF
+// fonts can show, under WinAnsiEncoding.
+var pdfWinAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92,
+	'“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+// pdfString encodes text as a PDF string in WinAnsiEncoding, replacing any
+// characters that cannot be shown.
+func pdfString(text string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		case pdfWinAnsi[r] != 0:
+			fmt.Fprintf(&b, "\\%03o", pdfWinAnsi[r])
+		default:
+			b.WriteByte('?')
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// pdfColour sets the fill, or stroke, colour.
+func pdfColour(colour string, op string) string {
+	r, g, b := parseColour(colour)
+	return fmt.Sprintf("%s %s %s %s", pdfNum(float64(r)/255), pdfNum(float64(g)/255), pdfNum(float64(b)/255), op)
+}
+
+func pdfNum(x float64) string {
+	return formatFloat(x)
+}
+
+// pdfRoundedRect adds a rounded rectangle to the current path.
+func pdfRoundedRect(b *strings.Builder, x, y, w, h, r float64) {
+	k := r * (1 - 0.5523) // distance of the control points from the corner
+	fmt.Fprintf(b, "%s %s m\n", pdfNum(x+r), pdfNum(y))
+	fmt.Fprintf(b, "%s %s l\n", pdfNum(x+w-r), pdfNum(y))
+	fmt.Fprintf(b, "%s %s %s %s %s %s c\n", pdfNum(x+w-k), pdfNum(y), pdfNum(x+w), pdfNum(y+k), pdfNum(x+w), pdfNum(y+r))
+	fmt.Fprintf(b, "%s %s l\n", pdfNum(x+w), pdfNum(y+h-r))
+	fmt.Fprintf(b, "%s %s %s %s %s %s c\n", pdfNum(x+w), pdfNum(y+h-k), pdfNum(x+w-k), pdfNum(y+h), pdfNum(x+w-r), pdfNum(y+h))
+	fmt.Fprintf(b, "%s %s l\n", pdfNum(x+r), pdfNum(y+h))
+	fmt.Fprintf(b, "%s %s %s %s %s %s c\n", pdfNum(x+k), pdfNum(y+h), pdfNum(x), pdfNum(y+h-k), pdfNum(x), pdfNum(y+h-r))
+	fmt.Fprintf(b, "%s %s l\n", pdfNum(x), pdfNum(y+r))
+	fmt.Fprintf(b, "%s %s %s %s %s %s c\nh\n", pdfNum(x), pdfNum(y+k), pdfNum(x+k), pdfNum(y), pdfNum(x+r), pdfNum(y))
+}
+
+// pdfDrawing draws the layout in its own coordinates, which the page maps
+// with a flipped y axis. Text is flipped back as it is drawn.
+func pdfDrawing(gl *graphLayout) string {
+	var b strings.Builder
+
+	// edges
+	b.WriteString(pdfColour("#555555", "RG") + "\n" + pdfColour("#555555", "rg") + "\n1.2 w\n")
+	for _, e := range gl.Edges {
+		curve := e.curve(gl.Dir)
+		if e.Back {
+			b.WriteString("[4 3] 0 d\n")
+		}
+		for i, p := range curve {
+			switch {
+			case i == 0:
+				fmt.Fprintf(&b, "%s %s m", pdfNum(p.X), pdfNum(p.Y))
+			case i%3 == 0:
+				fmt.Fprintf(&b, " %s %s c\n", pdfNum(p.X), pdfNum(p.Y))
+			default:
+				fmt.Fprintf(&b, " %s %s", pdfNum(p.X), pdfNum(p.Y))
+			}
+		}
+		b.WriteString("S\n")
+		if e.Back {
+			b.WriteString("[] 0 d\n")
+		}
+		head := arrowHead(curve)
+		fmt.Fprintf(&b, "%s %s m %s %s l %s %s l h f\n",
+			pdfNum(head[0].X), pdfNum(head[0].Y), pdfNum(head[1].X), pdfNum(head[1].Y), pdfNum(head[2].X), pdfNum(head[2].Y))
+	}
+
+	// nodes
+	b.WriteString("1 w\n")
+	for _, n := range gl.Nodes {
+		fill, text := nodeColours(n)
+		b.WriteString(pdfColour(fill, "rg") + "\n")
+		if n.Class == StatusIncomplete {
+			b.WriteString("[5 5] 0 d\n")
+		}
+		pdfRoundedRect(&b, n.X, n.Y, n.W, n.H, 6)
+		b.WriteString("B\n")
+		if n.Class == StatusIncomplete {
+			b.WriteString("[] 0 d\n")
+		}
+		b.WriteString("BT\n" + pdfColour(text, "rg") + "\n/F1 12 Tf\n")
+		for i, line := range n.Lines {
+			fmt.Fprintf(&b, "1 0 0 -1 %s %s Tm %s Tj\n",
+				pdfNum(n.X+layoutPadding), pdfNum(n.Y+layoutPadding+float64(i+1)*layoutLineHeight-4), pdfString(line))
+		}
+		fmt.Fprintf(&b, "/F1 10 Tf\n1 0 0 -1 %s %s Tm %s Tj\nET\n",
+			pdfNum(n.X+layoutPadding), pdfNum(n.Y+layoutPadding+float64(len(n.Lines)+1)*layoutLineHeight-4), pdfString(n.Key))
+		if x, y, _, h, filled := n.rollupBar(); n.Bar && filled > 0 {
+			fmt.Fprintf(&b, "%s\n%s %s %s %s re f\n", pdfColour(text, "rg"), pdfNum(x), pdfNum(y), pdfNum(filled), pdfNum(h))
+		}
+		b.WriteString(pdfColour("#555555", "rg") + "\n")
+	}
+	return b.String()
+}
+
+// pdfDocument collects numbered objects, and writes them out with their
+// cross reference table.
+type pdfDocument struct {
+	objects []string // object n is objects[n-1]
+}
+
+// reserve allocates an object number, for an object written later.
+func (d *pdfDocument) reserve() int {
+	d.objects = append(d.objects, "")
+	return len(d.objects)
+}
+
+func (d *pdfDocument) set(n int, body string) {
+	d.objects[n-1] = body
+}
+
+func (d *pdfDocument) add(body string) int {
+	n := d.reserve()
+	d.set(n, body)
+	return n
+}
+
+func (d *pdfDocument) stream(content string) int {
+	return d.add(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+}
+
+func (d *pdfDocument) write(writer io.Writer, root, info int) error {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(d.objects))
+	for i, body := range d.objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, body)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(d.objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(d.objects)+1, root, info, xref)
+	_, err := buf.WriteTo(writer)
+	return err
+}
+
+// WritePDF renders the graph as a vector PDF document, using the native
+// layout. The graph is scaled to fit the page set WithPageSize, or spread
+// across several pages WithTiling. Nodes link to their issues.
+func (tg *TaskGraph) WritePDF(writer io.Writer, opts ...RenderOption) error {
+
+	cfg, err := newRender(opts...)
+	if err != nil {
+		return err
+	}
+	v, err := tg.view(cfg)
+	if err != nil {
+		return err
+	}
+	gl := v.layout(cfg.dir)
+	drawing := pdfDrawing(gl)
+
+	d := &pdfDocument{}
+	catalog := d.reserve()
+	pages := d.reserve()
+	font := d.add("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	info := d.add(fmt.Sprintf("<< /Title %s /Producer %s >>", pdfString(cfg.theme.Title), pdfString("task-graph "+Version)))
+
+	var kids []string
+	for _, s := range cfg.sheets(gl) {
+		var content strings.Builder
+		content.WriteString("q\n")
+		if s.Clip {
+			fmt.Fprintf(&content, "%s %s %s %s re W n\n",
+				pdfNum(pageMargin), pdfNum(pageMargin), pdfNum(s.Width-2*pageMargin), pdfNum(s.Height-2*pageMargin))
+		}
+		fmt.Fprintf(&content, "%s 0 0 %s %s %s cm\n", pdfNum(s.Scale), pdfNum(-s.Scale), pdfNum(s.X), pdfNum(s.Height-s.Y))
+		content.WriteString(drawing)
+		content.WriteString("Q")
+		contents := d.stream(content.String())
+
+		// link annotations, for the nodes on the page
+		var annots []string
+		for _, n := range gl.Nodes {
+			if len(n.URL) == 0 {
+				continue
+			}
+			x1, x2 := s.X+n.X*s.Scale, s.X+(n.X+n.W)*s.Scale
+			y1, y2 := s.Height-(s.Y+(n.Y+n.H)*s.Scale), s.Height-(s.Y+n.Y*s.Scale)
+			if s.Clip {
+				x1, y1 = math.Max(x1, pageMargin), math.Max(y1, pageMargin)
+				x2, y2 = math.Min(x2, s.Width-pageMargin), math.Min(y2, s.Height-pageMargin)
+			}
+			if x1 >= x2 || y1 >= y2 {
+				continue
+			}
+			annot := d.add(fmt.Sprintf("<< /Type /Annot /Subtype /Link /Rect [%s %s %s %s] /Border [0 0 0] /A << /S /URI /URI %s >> >>",
+				pdfNum(x1), pdfNum(y1), pdfNum(x2), pdfNum(y2), pdfString(n.URL)))
+			annots = append(annots, fmt.Sprintf("%d 0 R", annot))
+		}
+
+		page := fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R",
+			pages, pdfNum(s.Width), pdfNum(s.Height), font, contents)
+		if len(annots) > 0 {
+			page += " /Annots [" + strings.Join(annots, " ") + "]"
+		}
+		kids = append(kids, fmt.Sprintf("%d 0 R", d.add(page+" >>")))
+	}
+
+	d.set(pages, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids)))
+	d.set(catalog, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pages))
+	return d.write(writer, catalog, info)
+}
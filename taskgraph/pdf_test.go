@@ -0,0 +1,110 @@
+package taskgraph
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// checkPDF checks that the cross reference table points at each object.
+func checkPDF(t *testing.T, pdf []byte) {
+	t.Helper()
+	m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(pdf)
+	if m == nil {
+		t.Fatalf("missing startxref:\n%s", pdf)
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	lines := strings.Split(string(pdf[xref:]), "\n")
+	if lines[0] != "xref" {
+		t.Fatalf("startxref does not point at the xref table")
+	}
+	count, _ := strconv.Atoi(strings.Fields(lines[1])[1])
+	for i := 1; i < count; i++ {
+		off, _ := strconv.Atoi(strings.Fields(lines[2+i])[0])
+		if !bytes.HasPrefix(pdf[off:], []byte(strconv.Itoa(i)+" 0 obj\n")) {
+			t.Errorf("xref entry %d does not point at its object", i)
+		}
+	}
+}
+
+func TestWritePDF(t *testing.T) {
+	tg := New()
+	addTask(tg, "o/r#1", `Release "one"`, StateOpen)
+	addTask(tg, "o/r#2", "Feature", StateClosed).StateReason = "not_planned"
+	addTask(tg, "o/s#3", "Docs", StateOpen).Labels = []string{"in progress"}
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/r#2"})
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/s#3"})
+	tg.AddEdge(Edge{From: "o/s#3", To: "o/s#4"})
+	tg.AddEdge(Edge{From: "o/r#2", To: "o/s#3", Kind: EdgeBlocks})
+	tg.Incomplete["o/s#4"] = &IssueRef{"o", "s", 4}
+	tg.Refs["o/r#1"].Title = "Release (“one”) €5 ✓"
+
+	var buf bytes.Buffer
+	if err := tg.WritePDF(&buf, WithPageSize(PageA4)); err != nil {
+		t.Fatal(err)
+	}
+	checkPDF(t, buf.Bytes())
+	out := buf.String()
+	for _, want := range []string{
+		"/MediaBox [0 0 595.28 841.89]",
+		"/Count 1 >>",
+		"/BaseFont /Helvetica",
+		`(Release \(\223one\224\) \2005 ?) Tj`,
+		"/URI (https://github.com/o/r/issues/1)",
+		"[5 5] 0 d",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if n := strings.Count(out, "/Subtype /Link"); n != 4 {
+		t.Errorf("expected 4 links, got %d", n)
+	}
+}
+
+func TestWritePDFTiling(t *testing.T) {
+	tg := New()
+	addTask(tg, "o/r#1", `Release "one"`, StateOpen)
+	addTask(tg, "o/r#2", "Feature", StateClosed).StateReason = "not_planned"
+	addTask(tg, "o/s#3", "Docs", StateOpen).Labels = []string{"in progress"}
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/r#2"})
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/s#3"})
+	tg.AddEdge(Edge{From: "o/s#3", To: "o/s#4"})
+	tg.AddEdge(Edge{From: "o/r#2", To: "o/s#3", Kind: EdgeBlocks})
+	tg.Incomplete["o/s#4"] = &IssueRef{"o", "s", 4}
+
+	var buf bytes.Buffer
+	if err := tg.WritePDF(&buf, WithPageSize(PageSize{200, 200}), WithTiling(true)); err != nil {
+		t.Fatal(err)
+	}
+	checkPDF(t, buf.Bytes())
+
+	// each tile shows 128pt square of the graph
+	gl := layout(tg)
+	cols, rows := int(gl.Width/128)+1, int(gl.Height/128)+1
+	if n := strings.Count(buf.String(), "/Type /Page "); n != cols*rows {
+		t.Errorf("expected %d pages, got %d", cols*rows, n)
+	}
+	if !strings.Contains(buf.String(), "36 36 128 128 re W n") {
+		t.Errorf("expected tiles to be clipped")
+	}
+}
+
+func TestParsePageSize(t *testing.T) {
+	for name, want := range map[string]PageSize{
+		"fit":          {},
+		"A4":           PageA4,
+		"a3-landscape": {PageA3.Height, PageA3.Width},
+		"letter":       PageLetter,
+	} {
+		got, err := ParsePageSize(name)
+		if err != nil || got != want {
+			t.Errorf("ParsePageSize(%q) = %v, %v, expected %v", name, got, err, want)
+		}
+	}
+	if _, err := ParsePageSize("b5"); err == nil {
+		t.Errorf("expected an error for an unknown page size")
+	}
+}
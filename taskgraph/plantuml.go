@@ -0,0 +1,100 @@
+package taskgraph
+
+import (
+	"io"
+	"strings"
+)
+
+// plantumlQuote quotes text as a PlantUML string. PlantUML has no escape for
+// a double quote within a string, so the unicode escape is used instead.
+func plantumlQuote(text string) string {
+	r := strings.NewReplacer(`"`, "<U+0022>", "\n", `\n`)
+	return `"` + r.Replace(text) + `"`
+}
+
+// plantumlDirections maps the graph direction onto a PlantUML directive.
+// PlantUML has no reversed directions, so edges are instead written from
+// their targets for BT and RL.
+var plantumlDirections = map[string]string{
+	"TB": "top to bottom direction",
+	"BT": "top to bottom direction",
+	"LR": "left to right direction",
+	"RL": "left to right direction",
+}
+
+// ToPlantUML renders the graph as a PlantUML diagram, with a rectangle per
+// group of tasks, by default per repository (see WithGrouping), enclosing the
+// rectangles of its tasks.
+func (tg *TaskGraph) ToPlantUML(writer io.Writer, opts ...RenderOption) error {
+
+	cfg, err := newRender(opts...)
+	if err != nil {
+		return err
+	}
+	v, err := tg.view(cfg)
+	if err != nil {
+		return err
+	}
+	w := &errWriter{w: writer}
+
+	w.printf("@startuml\n")
+	w.printf("title %s\n", cfg.theme.Title)
+	w.printf("%s\n", plantumlDirections[cfg.dir])
+	w.printf("skinparam rectangle {\n\tRoundCorner 10\n}\n")
+
+	// output nodes, within their group rectangles
+	node := func(n *viewNode, indent string) {
+		w.printf("%srectangle %s as %s", indent, plantumlQuote(v.rollupLabel(n, false)), n.ID)
+		if len(n.URL) > 0 {
+			w.printf(" [[%s{Open %s}]]", n.URL, n.Key)
+		}
+		if fill := n.Fill; len(fill) > 0 {
+			w.printf(" %s", fill)
+			if isDark(fill) {
+				w.printf(";text:white")
+			}
+			if n.Class == StatusIncomplete {
+				w.printf(";line.dashed")
+			}
+		}
+		w.printf("\n")
+	}
+	var group func(g *viewGroup, indent string)
+	group = func(g *viewGroup, indent string) {
+		w.printf("%srectangle %s as %s %s {\n", indent, plantumlQuote(g.Name), g.ID, cfg.theme.fill(PaletteGroup))
+		for _, n := range g.Nodes {
+			node(n, indent+"\t")
+		}
+		for _, sub := range g.Groups {
+			group(sub, indent+"\t")
+		}
+		w.printf("%s}\n", indent)
+	}
+	if len(v.Loose) > 0 {
+		w.printf("\n")
+	}
+	for _, n := range v.Loose {
+		node(n, "")
+	}
+	for _, g := range v.Groups {
+		w.printf("\n")
+		group(g, "")
+	}
+
+	// output edges
+	w.printf("\n")
+	for _, e := range v.Edges {
+		if reversed(cfg.dir) {
+			w.printf("%s <-- %s", e.To.ID, e.From.ID)
+		} else {
+			w.printf("%s --> %s", e.From.ID, e.To.ID)
+		}
+		if label := e.Label(); len(label) > 0 {
+			w.printf(" : %s", label)
+		}
+		w.printf("\n")
+	}
+
+	w.printf("@enduml\n")
+	return w.err
+}
@@ -0,0 +1,42 @@
+package taskgraph
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestToPlantUML(t *testing.T) {
+	tg := New()
+	addTask(tg, "o/r#1", `Release "one"`, StateOpen)
+	addTask(tg, "o/r#2", "Feature", StateClosed).StateReason = "not_planned"
+	addTask(tg, "o/s#3", "Docs", StateOpen).Labels = []string{"in progress"}
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/r#2"})
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/s#3"})
+	tg.AddEdge(Edge{From: "o/s#3", To: "o/s#4"})
+	tg.AddEdge(Edge{From: "o/r#2", To: "o/s#3", Kind: EdgeBlocks})
+	tg.Incomplete["o/s#4"] = &IssueRef{"o", "s", 4}
+
+	var buf bytes.Buffer
+	if err := tg.ToPlantUML(&buf, WithDirection("LR")); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"@startuml\n",
+		"left to right direction\n",
+		"rectangle \"r\" as ",
+		`rectangle "Release <U+0022>one<U+0022>" as tg_o_r_1 [[https://github.com/o/r/issues/1{Open o/r#1}]]` + "\n",
+		"#222222;text:white\n",
+		"#fff;line.dashed\n",
+		"tg_o_r_2 --> tg_o_s_3 : blocks\n",
+		"@enduml\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if n := strings.Count(out, " --> "); n != 4 {
+		t.Errorf("expected 4 edges, got %d in:\n%s", n, out)
+	}
+}
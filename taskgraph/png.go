@@ -0,0 +1,222 @@
+package taskgraph
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+var (
+	pngFontOnce sync.Once
+	pngFont     *opentype.Font
+	pngFontErr  error
+)
+
+// pngFace loads the embedded Go font at a size in pixels.
+func pngFace(size float64) (font.Face, error) {
+	pngFontOnce.Do(func() {
+		pngFont, pngFontErr = opentype.Parse(goregular.TTF)
+	})
+	if pngFontErr != nil {
+		return nil, pngFontErr
+	}
+	return opentype.NewFace(pngFont, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+}
+
+// pngCanvas draws in layout coordinates onto an image.
+type pngCanvas struct {
+	img   *image.RGBA
+	scale float64     // pixels per layout point
+	x, y  float64     // pixel offset of the layout origin
+	mask  image.Point // pixel origin of the shape being rasterized
+}
+
+func (c *pngCanvas) at(p point) (float32, float32) {
+	return float32(c.x + p.X*c.scale - float64(c.mask.X)), float32(c.y + p.Y*c.scale - float64(c.mask.Y))
+}
+
+// fill paints the shape that path traces. Shapes are rasterized within the
+// bounds of their extent, padded by pad points, rather than the whole image.
+func (c *pngCanvas) fill(colour string, extent []point, pad float64, path func(z *vector.Rasterizer)) {
+	lo, hi := point{math.Inf(1), math.Inf(1)}, point{math.Inf(-1), math.Inf(-1)}
+	for _, p := range extent {
+		lo = point{math.Min(lo.X, p.X-pad), math.Min(lo.Y, p.Y-pad)}
+		hi = point{math.Max(hi.X, p.X+pad), math.Max(hi.Y, p.Y+pad)}
+	}
+	bounds := image.Rect(
+		int(math.Floor(c.x+lo.X*c.scale)), int(math.Floor(c.y+lo.Y*c.scale)),
+		int(math.Ceil(c.x+hi.X*c.scale))+1, int(math.Ceil(c.y+hi.Y*c.scale))+1,
+	).Intersect(c.img.Bounds())
+	if bounds.Empty() {
+		return
+	}
+	c.mask = bounds.Min
+	z := vector.NewRasterizer(bounds.Dx(), bounds.Dy())
+	path(z)
+	r, g, b := parseColour(colour)
+	z.Draw(c.img, bounds, image.NewUniform(color.RGBA{r, g, b, 0xff}), image.Point{})
+}
+
+func (c *pngCanvas) polygon(z *vector.Rasterizer, ps ...point) {
+	z.MoveTo(c.at(ps[0]))
+	for _, p := range ps[1:] {
+		z.LineTo(c.at(p))
+	}
+	z.ClosePath()
+}
+
+// roundedRect traces a rounded rectangle, inset by d points.
+func (c *pngCanvas) roundedRect(z *vector.Rasterizer, x, y, w, h, r, d float64) {
+	x, y, w, h, r = x+d, y+d, w-2*d, h-2*d, math.Max(0, r-d)
+	z.MoveTo(c.at(point{x + r, y}))
+	z.LineTo(c.at(point{x + w - r, y}))
+	z.QuadTo(c.at2(point{x + w, y}, point{x + w, y + r}))
+	z.LineTo(c.at(point{x + w, y + h - r}))
+	z.QuadTo(c.at2(point{x + w, y + h}, point{x + w - r, y + h}))
+	z.LineTo(c.at(point{x + r, y + h}))
+	z.QuadTo(c.at2(point{x, y + h}, point{x, y + h - r}))
+	z.LineTo(c.at(point{x, y + r}))
+	z.QuadTo(c.at2(point{x, y}, point{x + r, y}))
+	z.ClosePath()
+}
+
+func (c *pngCanvas) at2(p, q point) (float32, float32, float32, float32) {
+	px, py := c.at(p)
+	qx, qy := c.at(q)
+	return px, py, qx, qy
+}
+
+// stroke draws a line of a given width, dashed if dash is non-zero.
+func (c *pngCanvas) stroke(colour string, line []point, width, dash float64) {
+	c.fill(colour, line, width, func(z *vector.Rasterizer) {
+		segment := func(a, b point) {
+			dx, dy := b.X-a.X, b.Y-a.Y
+			d := math.Hypot(dx, dy)
+			if d == 0 {
+				return
+			}
+			nx, ny := -dy/d*width/2, dx/d*width/2
+			c.polygon(z, point{a.X + nx, a.Y + ny}, point{b.X + nx, b.Y + ny}, point{b.X - nx, b.Y - ny}, point{a.X - nx, a.Y - ny})
+		}
+		travelled := 0.0 // along the line, for dashing
+		for i := 1; i < len(line); i++ {
+			a, b := line[i-1], line[i]
+			d := math.Hypot(b.X-a.X, b.Y-a.Y)
+			if dash <= 0 {
+				segment(a, b)
+				continue
+			}
+			// split the segment where dashes start and stop
+			for t := 0.0; t < d; {
+				on := int(travelled/dash)%2 == 0
+				step := math.Min(d-t, dash-math.Mod(travelled, dash))
+				if on {
+					segment(
+						point{a.X + (b.X-a.X)*t/d, a.Y + (b.Y-a.Y)*t/d},
+						point{a.X + (b.X-a.X)*(t+step)/d, a.Y + (b.Y-a.Y)*(t+step)/d},
+					)
+				}
+				t += step
+				travelled += step
+			}
+		}
+	})
+}
+
+// text draws a line of text with its baseline at p.
+func (c *pngCanvas) text(face font.Face, colour string, p point, s string) {
+	r, g, b := parseColour(colour)
+	c.mask = image.Point{}
+	x, y := c.at(p)
+	d := &font.Drawer{
+		Dst:  c.img,
+		Src:  image.NewUniform(color.RGBA{r, g, b, 0xff}),
+		Face: face,
+		Dot:  fixed.Point26_6{X: fixed.Int26_6(x * 64), Y: fixed.Int26_6(y * 64)},
+	}
+	d.DrawString(s)
+}
+
+// WritePNG renders the graph as a PNG image, using the native layout, at the
+// resolution set WithDPI. If a page is set WithPageSize, the graph is scaled
+// to fit it. Tiling needs several pages, so is only supported by WritePDF.
+func (tg *TaskGraph) WritePNG(writer io.Writer, opts ...RenderOption) error {
+
+	cfg, err := newRender(opts...)
+	if err != nil {
+		return err
+	}
+	if cfg.tile {
+		return errors.New("tiling is only supported for PDF")
+	}
+	v, err := tg.view(cfg)
+	if err != nil {
+		return err
+	}
+	gl := v.layout(cfg.dir)
+	s := cfg.sheets(gl)[0]
+
+	px := cfg.dpi / 72 // pixels per point
+	w, h := int(math.Ceil(s.Width*px)), int(math.Ceil(s.Height*px))
+	c := &pngCanvas{
+		img:   image.NewRGBA(image.Rect(0, 0, w, h)),
+		scale: s.Scale * px,
+		x:     s.X * px,
+		y:     s.Y * px,
+	}
+	draw.Draw(c.img, c.img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	title, err := pngFace(12 * c.scale)
+	if err != nil {
+		return err
+	}
+	ref, err := pngFace(10 * c.scale)
+	if err != nil {
+		return err
+	}
+
+	// edges
+	for _, e := range gl.Edges {
+		curve := e.curve(gl.Dir)
+		dash := 0.0
+		if e.Back {
+			dash = 4
+		}
+		c.stroke("#555555", flatten(curve, 16), 1.2, dash)
+		head := arrowHead(curve)
+		c.fill("#555555", head[:], 0, func(z *vector.Rasterizer) { c.polygon(z, head[:]...) })
+	}
+
+	// nodes, with their border drawn under an inset fill
+	for _, n := range gl.Nodes {
+		fill, text := nodeColours(n)
+		border := "#555555"
+		if n.Class == StatusIncomplete {
+			border = "#aaaaaa"
+		}
+		box := []point{{n.X, n.Y}, {n.X + n.W, n.Y + n.H}}
+		c.fill(border, box, 0, func(z *vector.Rasterizer) { c.roundedRect(z, n.X, n.Y, n.W, n.H, 6, 0) })
+		c.fill(fill, box, 0, func(z *vector.Rasterizer) { c.roundedRect(z, n.X, n.Y, n.W, n.H, 6, 1) })
+		for i, line := range n.Lines {
+			c.text(title, text, point{n.X + layoutPadding, n.Y + layoutPadding + float64(i+1)*layoutLineHeight - 4}, line)
+		}
+		c.text(ref, text, point{n.X + layoutPadding, n.Y + layoutPadding + float64(len(n.Lines)+1)*layoutLineHeight - 4}, n.Key)
+		if x, y, _, h, filled := n.rollupBar(); n.Bar && filled > 0 {
+			bar := []point{{x, y}, {x + filled, y}, {x + filled, y + h}, {x, y + h}}
+			c.fill(text, bar, 0, func(z *vector.Rasterizer) { c.polygon(z, bar...) })
+		}
+	}
+
+	return png.Encode(writer, c.img)
+}
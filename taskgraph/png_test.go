@@ -0,0 +1,58 @@
+package taskgraph
+
+import (
+	"bytes"
+	"image/png"
+	"math"
+	"testing"
+)
+
+func TestWritePNG(t *testing.T) {
+	tg := New()
+	addTask(tg, "o/r#1", `Release "one"`, StateOpen)
+	addTask(tg, "o/r#2", "Feature", StateClosed).StateReason = "not_planned"
+	addTask(tg, "o/s#3", "Docs", StateOpen).Labels = []string{"in progress"}
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/r#2"})
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/s#3"})
+	tg.AddEdge(Edge{From: "o/s#3", To: "o/s#4"})
+	tg.AddEdge(Edge{From: "o/r#2", To: "o/s#3", Kind: EdgeBlocks})
+	tg.Incomplete["o/s#4"] = &IssueRef{"o", "s", 4}
+	gl := layout(tg)
+
+	for _, dpi := range []float64{72, 150} {
+		var buf bytes.Buffer
+		if err := tg.WritePNG(&buf, WithDPI(dpi)); err != nil {
+			t.Fatal(err)
+		}
+		img, err := png.Decode(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b := img.Bounds()
+		if w, h := int(math.Ceil(gl.Width*dpi/72)), int(math.Ceil(gl.Height*dpi/72)); b.Dx() != w || b.Dy() != h {
+			t.Errorf("at %v dpi expected %dx%d, got %dx%d", dpi, w, h, b.Dx(), b.Dy())
+		}
+		// the abandoned task is filled with its colour
+		n := layoutNodeByRef(gl, "o/r#2")
+		r, g, bl, _ := img.At(int((n.X+n.W-4)*dpi/72), int((n.Y+4)*dpi/72)).RGBA()
+		if r>>8 != 0x22 || g>>8 != 0x22 || bl>>8 != 0x22 {
+			t.Errorf("at %v dpi expected #222222, got %x %x %x", dpi, r>>8, g>>8, bl>>8)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := tg.WritePNG(&buf, WithPageSize(PageA4)); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != int(math.Ceil(PageA4.Width*DefaultDPI/72)) {
+		t.Errorf("expected an A4 image, got %v", b)
+	}
+
+	if err := tg.WritePNG(&buf, WithPageSize(PageA4), WithTiling(true)); err == nil {
+		t.Errorf("expected tiling to be refused")
+	}
+}
@@ -0,0 +1,224 @@
+package taskgraph
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// RenderOption configures how a TaskGraph is rendered.
+type RenderOption func(*render)
+
+// render holds the settings shared by all of the renderers.
+type render struct {
+	dir    string
+	status *StatusMap
+	filter func(*Task) bool
+	group  Grouping
+	theme  *Theme
+	label  *template.Template
+	dpi    float64
+	page   PageSize
+	tile   bool
+
+	rollup    bool
+	estimates []string
+	critical  bool
+
+	startFields []string
+	dueFields   []string
+
+	depth     int
+	collapse  bool
+	ascii     bool
+	colour    bool
+	hyperlink bool
+
+	nodeColumns []string
+	edgeColumns []string
+	separator   rune
+}
+
+func newRender(opts ...RenderOption) (*render, error) {
+	r := &render{
+		status: DefaultStatusMap(),
+		group:  Grouping{By: GroupRepo},
+		theme:  DefaultTheme(),
+		dpi:    DefaultDPI,
+
+		startFields: DefaultStartFields,
+		dueFields:   DefaultDueFields,
+
+		edgeColumns: DefaultEdgeColumns,
+		separator:   ',',
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.nodeColumns == nil {
+		r.nodeColumns = DefaultNodeColumns
+		if r.rollup {
+			r.nodeColumns = append(append([]string{}, DefaultNodeColumns...), RollupColumns...)
+		}
+	}
+	if err := r.theme.validate(); err != nil {
+		return nil, err
+	}
+	if len(r.dir) == 0 {
+		r.dir = r.theme.Direction
+	}
+	if len(r.dir) == 0 {
+		r.dir = "TB"
+	}
+	if !isDirection(r.dir) {
+		return nil, fmt.Errorf("bad graph direction: %s", r.dir)
+	}
+	if r.dpi <= 0 {
+		return nil, fmt.Errorf("bad resolution: %v dpi", r.dpi)
+	}
+	if r.page.Width < 0 || r.page.Height < 0 {
+		return nil, fmt.Errorf("bad page size: %v", r.page)
+	}
+	if err := r.group.validate(); err != nil {
+		return nil, err
+	}
+	if r.depth < 0 {
+		return nil, fmt.Errorf("bad depth: %d", r.depth)
+	}
+	for _, name := range r.nodeColumns {
+		if _, ok := csvNodeColumn(name); !ok {
+			return nil, fmt.Errorf("unknown node column: %s (want one of %s)", name, strings.Join(NodeColumns(), ", "))
+		}
+	}
+	for _, name := range r.edgeColumns {
+		if _, ok := csvEdgeColumn(name); !ok {
+			return nil, fmt.Errorf("unknown edge column: %s (want one of %s)", name, strings.Join(EdgeColumns(), ", "))
+		}
+	}
+	if r.tile && r.page == (PageSize{}) {
+		return nil, fmt.Errorf("tiling needs a page size")
+	}
+	return r, nil
+}
+
+// WithDirection sets the flow direction of the graph, one of Directions, over
+// that of the theme. By default graphs flow from top to bottom, "TB".
+func WithDirection(dir string) RenderOption {
+	return func(r *render) {
+		r.dir = dir
+	}
+}
+
+// WithStatusMap sets how tasks are mapped onto status classes, in place of
+// DefaultStatusMap.
+func WithStatusMap(sm *StatusMap) RenderOption {
+	return func(r *render) {
+		r.status = sm
+	}
+}
+
+// WithFilter only renders the tasks for which the filter returns true, along
+// with the edges between them. Tasks that were never fetched are always shown.
+func WithFilter(filter func(*Task) bool) RenderOption {
+	return func(r *render) {
+		r.filter = filter
+	}
+}
+
+// WithDPI sets the resolution of raster images, DefaultDPI by default.
+func WithDPI(dpi float64) RenderOption {
+	return func(r *render) {
+		r.dpi = dpi
+	}
+}
+
+// WithPageSize sets the page that images and documents are drawn on. The
+// graph is scaled down to fit the page, unless it is tiled. By default the
+// page is the size of the graph.
+func WithPageSize(page PageSize) RenderOption {
+	return func(r *render) {
+		r.page = page
+	}
+}
+
+// WithTiling spreads graphs that are too large for a page across several
+// pages, at full size, rather than scaling them down to fit.
+func WithTiling(tile bool) RenderOption {
+	return func(r *render) {
+		r.tile = tile
+	}
+}
+
+// HideClosed is a filter that hides closed tasks.
+func HideClosed(t *Task) bool {
+	return !t.IsClosed()
+}
+
+// classFills are the fill colours of the status classes.
+var classFills = map[string]string{
+	StatusClosed:     "#ccc",
+	StatusAbandoned:  "#222222",
+	StatusCompleted:  "#37e519",
+	StatusReview:     "#f55a00",
+	StatusActive:     "#e5b104",
+	StatusParked:     "#b37fcd",
+	StatusPending:    "#60a1ea",
+	StatusStaged:     "#f07ee9",
+	StatusIncomplete: "#fff",
+}
+
+// styledClasses lists every class that the renderers style.
+func styledClasses() []string {
+	classes := make([]string, 0, len(StatusClasses)+1)
+	classes = append(classes, StatusClasses...)
+	return append(classes, StatusIncomplete)
+}
+
+// nodeIDs hands out the node identifiers used by the renderers. They are
+// derived from the task references, e.g. "tg_o_r_1" for o/r#1, so that they
+// do not change from one render to the next, and are safe in every format.
+type nodeIDs struct {
+	ids  map[string]string // taskref -> nodeid
+	used map[string]bool
+}
+
+func newNodeIDs(size int) *nodeIDs {
+	return &nodeIDs{ids: make(map[string]string, size), used: make(map[string]bool, size)}
+}
+
+func (n *nodeIDs) id(taskref string) string {
+	x, ok := n.ids[taskref]
+	if !ok {
+		base := "tg_" + strings.Map(func(r rune) rune {
+			if r < 0x80 && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+				return r
+			}
+			return '_'
+		}, taskref)
+		// references that differ only in punctuation, e.g. o/a-b#1 and
+		// o/a.b#1, are told apart in the order they are first seen
+		x = base
+		for i := 2; n.used[x]; i++ {
+			x = fmt.Sprintf("%s_%d", base, i)
+		}
+		n.ids[taskref] = x
+		n.used[x] = true
+	}
+	return x
+}
+
+// errWriter remembers the first write error, so that a sequence of writes can
+// be checked once.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) printf(format string, args ...any) {
+	if ew.err != nil {
+		return
+	}
+	_, ew.err = fmt.Fprintf(ew.w, format, args...)
+}
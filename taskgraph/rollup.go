@@ -0,0 +1,133 @@
+package taskgraph
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultEstimateFields are the project fields, and front-matter keys, that
+// may hold the estimate of a task. They are matched case insensitively, and
+// the first present wins.
+var DefaultEstimateFields = []string{"Estimate", "Story points", "Points"}
+
+// Rollup is how far along the descendants of a task are, i.e. the tasks that
+// can be reached through its tasklists. Each descendant counts once, however
+// many paths lead to it, and tasks closed as not planned do not count at all.
+type Rollup struct {
+	Closed float64 // closed descendants, or the sum of their estimates
+	Total  float64 // all descendants, or the sum of their estimates
+}
+
+// Percent is the closed share of the total, rounded down so that 100% means
+// done.
+func (r Rollup) Percent() int {
+	if r.Total <= 0 {
+		return 0
+	}
+	return int(math.Floor(100 * r.Closed / r.Total))
+}
+
+func (r Rollup) String() string {
+	return strconv.Itoa(r.Percent()) + "% (" + formatFloat(r.Closed) + "/" + formatFloat(r.Total) + ")"
+}
+
+// bar draws the rollup as a bar of n cells, e.g. "███░░░░░░░".
+func (r Rollup) bar(n int) string {
+	full := 0
+	if r.Total > 0 {
+		full = int(math.Floor(float64(n) * r.Closed / r.Total))
+	}
+	return strings.Repeat("█", full) + strings.Repeat("░", n-full)
+}
+
+// WithRollup shows the rollup of each task with descendants, see Rollup, in
+// the graphs, images and outlines.
+func WithRollup(show bool) RenderOption {
+	return func(r *render) {
+		r.rollup = show
+	}
+}
+
+// WithEstimates weighs each task by its estimate, read from the first of the
+// given project fields or front-matter keys that it has, e.g.
+// DefaultEstimateFields, in place of counting tasks. Tasks without an
+// estimate, such as most epics, then weigh nothing.
+func WithEstimates(fields []string) RenderOption {
+	return func(r *render) {
+		r.estimates = fields
+	}
+}
+
+// rollupLabel is the label of a node, followed by its rollup if rollups are
+// shown, with a bar if asked for.
+func (v *view) rollupLabel(n *viewNode, bar bool) string {
+	if !v.Rollups || n.Rollup == nil {
+		return n.Label
+	}
+	if bar {
+		return n.Label + "\n" + n.Rollup.bar(10) + " " + n.Rollup.String()
+	}
+	return n.Label + "\n" + n.Rollup.String()
+}
+
+// taskEstimate finds the estimate of a task, first in its project fields and
+// then in the front-matter of its body.
+func taskEstimate(t *Task, names []string) (float64, bool) {
+	return taskField(t, frontMatter(t.Body), names, func(s string) (float64, bool) {
+		x, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		return x, err == nil && x >= 0 && !math.IsInf(x, 0)
+	})
+}
+
+// weight is what a task counts for in a rollup, and whether it is closed, or
+// not ok if the task does not count at all.
+func (tg *TaskGraph) weight(ref string, estimates []string) (w float64, closed bool, ok bool) {
+	t, fetched := tg.Refs[ref]
+	switch {
+	case !fetched && estimates == nil:
+		return 1, false, true
+	case !fetched:
+		// never fetched, so open, and without an estimate
+		return 0, false, true
+	case t.IsClosed() && t.StateReason == "not_planned":
+		return 0, false, false
+	case estimates == nil:
+		return 1, t.IsClosed(), true
+	}
+	w, _ = taskEstimate(t, estimates)
+	return w, t.IsClosed(), true
+}
+
+// Rollup computes, for every task with descendants, how many of them are
+// closed, weighted by their estimates if any estimate fields are given, as by
+// WithEstimates. The rollup covers the whole graph, whatever is filtered out
+// when rendering.
+func (tg *TaskGraph) Rollup(estimates []string) map[string]Rollup {
+	rollups := make(map[string]Rollup)
+	for _, ref := range sortedKeys(tg.Edges) {
+		stack := tg.tasklist(ref)
+		if len(stack) == 0 {
+			continue
+		}
+		var r Rollup
+		seen := map[string]bool{ref: true}
+		for len(stack) > 0 {
+			d := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if seen[d] {
+				continue
+			}
+			seen[d] = true
+			stack = append(stack, tg.tasklist(d)...)
+			if w, closed, ok := tg.weight(d, estimates); ok {
+				r.Total += w
+				if closed {
+					r.Closed += w
+				}
+			}
+		}
+		rollups[ref] = r
+	}
+	return rollups
+}
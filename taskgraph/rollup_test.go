@@ -0,0 +1,112 @@
+package taskgraph
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRollup(t *testing.T) {
+	// an epic with two features that share a subtask, one closed subtask, one
+	// abandoned subtask and one that was never fetched
+	tg := New()
+	tg.Incomplete["o/r#6"] = &IssueRef{Owner: "o", Repo: "r", Number: 6}
+	link(tg, "o/r#1", "o/r#2", "o/r#3")
+	link(tg, "o/r#2", "o/r#4", "o/r#6")
+	link(tg, "o/r#3", "o/r#4", "o/r#5")
+	tg.Refs["o/r#2"].Fields = map[string]string{"Estimate": "5"}
+	tg.Refs["o/r#3"].State = StateClosed
+	tg.Refs["o/r#3"].Fields = map[string]string{"estimate": "3"}
+	tg.Refs["o/r#4"].State = StateClosed
+	tg.Refs["o/r#4"].Body = "---\npoints: 2\n---\n"
+	dropped := tg.Refs["o/r#5"]
+	dropped.State, dropped.StateReason = StateClosed, "not_planned"
+	dropped.Fields = map[string]string{"Estimate": "8"}
+
+	// counting, with the shared subtask counted once
+	got := tg.Rollup(nil)
+	for ref, want := range map[string]Rollup{
+		"o/r#1": {Closed: 2, Total: 4},
+		"o/r#2": {Closed: 1, Total: 2},
+		"o/r#3": {Closed: 1, Total: 1},
+	} {
+		if got[ref] != want {
+			t.Errorf("%s: expected %+v, got %+v", ref, want, got[ref])
+		}
+	}
+	if _, ok := got["o/r#4"]; ok {
+		t.Errorf("expected no rollup for a task without descendants")
+	}
+	tg.AddEdge(Edge{From: "o/r#4", To: "o/r#5", Kind: EdgeBlocks})
+	if _, ok := tg.Rollup(nil)["o/r#4"]; ok {
+		t.Errorf("expected no rollup through a blocks edge")
+	}
+
+	// weighted, with the unfetched task unknown
+	got = tg.Rollup(DefaultEstimateFields)
+	if want := (Rollup{Closed: 5, Total: 10}); got["o/r#1"] != want {
+		t.Errorf("expected %+v, got %+v", want, got["o/r#1"])
+	}
+	if p := got["o/r#1"].Percent(); p != 50 {
+		t.Errorf("expected 50%%, got %d%%", p)
+	}
+	if s := (Rollup{Closed: 2, Total: 3}).String(); s != "66% (2/3)" {
+		t.Errorf("expected 66%% (2/3), got %s", s)
+	}
+
+	// cycles do not count a task as its own descendant
+	tg.AddEdge(Edge{From: "o/r#4", To: "o/r#1"})
+	if r := tg.Rollup(nil)["o/r#1"]; r.Total != 4 {
+		t.Errorf("expected a total of 4 on a cycle, got %+v", r)
+	}
+}
+
+func TestRollupRendering(t *testing.T) {
+	// the docs have a closed subtask, and one that was never fetched
+	tg := New()
+	addTask(tg, "o/r#1", `Release "one"`, StateOpen)
+	addTask(tg, "o/r#2", "Feature", StateClosed).StateReason = "not_planned"
+	addTask(tg, "o/s#3", "Docs", StateOpen).Labels = []string{"in progress"}
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/r#2"})
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/s#3"})
+	tg.AddEdge(Edge{From: "o/s#3", To: "o/s#4"})
+	tg.AddEdge(Edge{From: "o/r#2", To: "o/s#3", Kind: EdgeBlocks})
+	tg.Incomplete["o/s#4"] = &IssueRef{"o", "s", 4}
+	link(tg, "o/s#3", "o/s#5")
+	tg.Refs["o/s#5"].State = StateClosed
+
+	for _, c := range []struct {
+		name   string
+		render func(*bytes.Buffer, ...RenderOption) error
+		want   string
+	}{
+		{"mermaid", func(b *bytes.Buffer, opts ...RenderOption) error { return tg.ToMermaid(b, opts...) },
+			`tg_o_s_3["Docs<br/>█████░░░░░ 50% (1/2)"]`},
+		{"dot", func(b *bytes.Buffer, opts ...RenderOption) error { return tg.ToDot(b, opts...) },
+			`label="Docs\n50% (1/2)"`},
+		{"svg", func(b *bytes.Buffer, opts ...RenderOption) error { return tg.ToSVG(b, opts...) },
+			`<rect class="rollup" x="10" y="60" width="90" height="3"`},
+		{"json", func(b *bytes.Buffer, opts ...RenderOption) error {
+			return tg.WriteJSON(b, append(opts, WithColumns([]string{"ref", "rollup_closed", "rollup_total", "rollup_percent"}, nil))...)
+		}, `{"ref": "o/s#3", "rollup_closed": 1, "rollup_total": 2, "rollup_percent": 50},`},
+		{"csv", func(b *bytes.Buffer, opts ...RenderOption) error { return tg.WriteCSV(b, opts...) },
+			",depth,url,rollup_closed,rollup_total,rollup_percent\n"},
+	} {
+		var buf bytes.Buffer
+		if err := c.render(&buf, WithRollup(true)); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(buf.String(), c.want) {
+			t.Errorf("%s: expected %q in:\n%s", c.name, c.want, buf.String())
+		}
+
+		// only shown when asked for, though always in the tables that pick them
+		buf.Reset()
+		if err := c.render(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if shown := strings.Contains(buf.String(), c.want); shown != (c.name == "json") {
+			t.Errorf("%s: expected the rollup to be shown only when asked for, got:\n%s", c.name, buf.String())
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package taskgraph
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// SnapshotVersion is the version of the snapshot format written by
+// WriteSnapshot. ReadSnapshot accepts this, and any earlier, version.
+const SnapshotVersion = 1
+
+// SnapshotSchema is the JSON Schema describing the snapshot format.
+//
+//go:embed snapshot.schema.json
+var SnapshotSchema string
+
+// snapshot is the serialised form of a task graph. It is kept separate from
+// the in-memory model so that the two can evolve independently.
+type snapshot struct {
+	Version    int              `json:"version"`
+	Generated  time.Time        `json:"generated"`
+	Roots      []string         `json:"roots"`
+	Nodes      []*snapshotTask  `json:"nodes"`
+	Edges      []*snapshotEdge  `json:"edges"`
+	Incomplete []*snapshotIssue `json:"incomplete,omitempty"`
+}
+
+type snapshotIssue struct {
+	ID     string `json:"id"`
+	Owner  string `json:"owner"`
+	Repo   string `json:"repo"`
+	Number int    `json:"number"`
+}
+
+type snapshotTask struct {
+	snapshotIssue
+	URL          string            `json:"url,omitempty"`
+	Title        string            `json:"title"`
+	Body         string            `json:"body,omitempty"`
+	Kind         string            `json:"kind,omitempty"`
+	State        string            `json:"state"`
+	StateReason  string            `json:"state_reason,omitempty"`
+	Labels       []string          `json:"labels,omitempty"`
+	Assignees    []string          `json:"assignees,omitempty"`
+	Milestone    string            `json:"milestone,omitempty"`
+	MilestoneDue *time.Time        `json:"milestone_due,omitempty"`
+	Created      *time.Time        `json:"created,omitempty"`
+	Updated      *time.Time        `json:"updated,omitempty"`
+	Closed       *time.Time        `json:"closed,omitempty"`
+	Fetched      *time.Time        `json:"fetched,omitempty"`
+	Fields       map[string]string `json:"fields,omitempty"`
+}
+
+type snapshotEdge struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Kind    string `json:"kind"`
+	Checked bool   `json:"checked"`
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func requiredTime(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// WriteSnapshot writes the graph as an indented JSON snapshot, which can be
+// read back with ReadSnapshot.
+func (tg *TaskGraph) WriteSnapshot(writer io.Writer) error {
+	snap := snapshot{
+		Version:   SnapshotVersion,
+		Generated: time.Now().UTC(),
+		Roots:     tg.Roots,
+		Nodes:     make([]*snapshotTask, 0, len(tg.Refs)),
+		Edges:     make([]*snapshotEdge, 0, len(tg.Edges)),
+	}
+	if snap.Roots == nil {
+		snap.Roots = []string{}
+	}
+
+	for _, k := range sortedKeys(tg.Refs) {
+		t := tg.Refs[k]
+		snap.Nodes = append(snap.Nodes, &snapshotTask{
+			snapshotIssue: snapshotIssue{k, t.Owner, t.Repo, t.Number},
+			URL:           t.URL,
+			Title:         t.Title,
+			Body:          t.Body,
+			Kind:          t.Kind,
+			State:         t.State,
+			StateReason:   t.StateReason,
+			Labels:        t.Labels,
+			Assignees:     t.Assignees,
+			Milestone:     t.Milestone,
+			MilestoneDue:  optionalTime(t.MilestoneDue),
+			Created:       optionalTime(t.Created),
+			Updated:       optionalTime(t.Updated),
+			Closed:        optionalTime(t.Closed),
+			Fetched:       optionalTime(t.Fetched),
+			Fields:        t.Fields,
+		})
+	}
+	for _, from := range sortedKeys(tg.Edges) {
+		for _, to := range tg.Edges[from] {
+			e := tg.Edge(from, to)
+			snap.Edges = append(snap.Edges, &snapshotEdge{e.From, e.To, e.Kind, e.Checked})
+		}
+	}
+	for _, k := range sortedKeys(tg.Incomplete) {
+		r := tg.Incomplete[k]
+		snap.Incomplete = append(snap.Incomplete, &snapshotIssue{k, r.Owner, r.Repo, r.Number})
+	}
+
+	enc := json.NewEncoder(writer)
+	enc.SetIndent("", "  ")
+	return enc.Encode(&snap)
+}
+
+// ReadSnapshot reads a graph that was written by WriteSnapshot. The graph is
+// configured with the given options, so that it can be extended by further
+// traversals.
+func ReadSnapshot(reader io.Reader, opts ...Option) (*TaskGraph, error) {
+	var snap snapshot
+	dec := json.NewDecoder(reader)
+	if err := dec.Decode(&snap); err != nil {
+		return nil, fmt.Errorf("bad snapshot: %w", err)
+	}
+	if snap.Version < 1 || snap.Version > SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version: %d", snap.Version)
+	}
+
+	tg := New(opts...)
+	tg.Roots = snap.Roots
+	for _, n := range snap.Nodes {
+		if len(n.ID) == 0 {
+			return nil, fmt.Errorf("bad snapshot: node without an id")
+		}
+		tg.Refs[n.ID] = &Task{
+			IssueRef:     &IssueRef{n.Owner, n.Repo, n.Number},
+			ID:           n.ID,
+			URL:          n.URL,
+			Title:        n.Title,
+			Body:         n.Body,
+			Kind:         n.Kind,
+			State:        n.State,
+			StateReason:  n.StateReason,
+			Labels:       n.Labels,
+			Assignees:    n.Assignees,
+			Milestone:    n.Milestone,
+			MilestoneDue: requiredTime(n.MilestoneDue),
+			Created:      requiredTime(n.Created),
+			Updated:      requiredTime(n.Updated),
+			Closed:       requiredTime(n.Closed),
+			Fetched:      requiredTime(n.Fetched),
+			Fields:       n.Fields,
+		}
+		tg.Edges[n.ID] = []string{}
+	}
+	for _, e := range snap.Edges {
+		tg.AddEdge(Edge{From: e.From, To: e.To, Kind: e.Kind, Checked: e.Checked})
+	}
+	for _, r := range snap.Incomplete {
+		tg.Incomplete[r.ID] = &IssueRef{r.Owner, r.Repo, r.Number}
+	}
+	return tg, nil
+}
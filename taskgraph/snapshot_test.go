@@ -0,0 +1,81 @@
+package taskgraph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v52/github"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	fs := diamond()
+	fs.issues["o/r#2"].Body = github.String("```[tasklist]\n- [x] #5\n```\n")
+	fs.issues["o/r#2"].Labels = []*github.Label{{Name: github.String("bug")}}
+
+	tg := New()
+	if err := tg.AccumulateFrom(context.Background(), fs, &IssueRef{"o", "r", 1}); err != nil {
+		t.Fatal(err)
+	}
+	tg.Incomplete["o/r#9"] = &IssueRef{"o", "r", 9}
+
+	if !tg.Edge("o/r#2", "o/r#5").Checked || tg.Edge("o/r#3", "o/r#5").Checked {
+		t.Errorf("expected only o/r#2 -> o/r#5 to be checked")
+	}
+
+	var buf bytes.Buffer
+	if err := tg.WriteSnapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+	back, err := ReadSnapshot(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(back.Roots, []string{"o/r#1"}) {
+		t.Errorf("unexpected roots %v", back.Roots)
+	}
+	if len(back.Refs) != len(tg.Refs) {
+		t.Fatalf("expected %d nodes, got %d", len(tg.Refs), len(back.Refs))
+	}
+	for k, want := range tg.Refs {
+		got := back.Refs[k]
+		if got == nil || got.Title != want.Title || got.State != want.State ||
+			!reflect.DeepEqual(got.Labels, want.Labels) || !got.Fetched.Equal(want.Fetched) {
+			t.Errorf("node %v: expected %+v, got %+v", k, want, got)
+		}
+	}
+	for from, dsts := range tg.Edges {
+		if strings.Join(back.Edges[from], ",") != strings.Join(dsts, ",") {
+			t.Errorf("edges from %v: expected %v, got %v", from, dsts, back.Edges[from])
+		}
+		for _, to := range dsts {
+			if back.Edge(from, to) != tg.Edge(from, to) {
+				t.Errorf("edge %v -> %v: expected %+v, got %+v", from, to, tg.Edge(from, to), back.Edge(from, to))
+			}
+		}
+	}
+	if _, ok := back.Incomplete["o/r#9"]; !ok || len(back.Incomplete) != 1 {
+		t.Errorf("unexpected incomplete %v", back.Incomplete)
+	}
+}
+
+func TestSnapshotVersion(t *testing.T) {
+	_, err := ReadSnapshot(strings.NewReader(`{"version": 99, "roots": [], "nodes": [], "edges": []}`))
+	if err == nil {
+		t.Errorf("expected an unsupported version to be rejected")
+	}
+}
+
+func TestSnapshotSchema(t *testing.T) {
+	var schema map[string]any
+	if err := json.Unmarshal([]byte(SnapshotSchema), &schema); err != nil {
+		t.Fatal(err)
+	}
+	if schema["properties"].(map[string]any)["version"].(map[string]any)["const"] != float64(SnapshotVersion) {
+		t.Errorf("expected the schema to describe snapshot version %d", SnapshotVersion)
+	}
+}
@@ -0,0 +1,146 @@
+package taskgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Status classes, as used to colour rendered tasks.
+const (
+	StatusClosed     = "closed"
+	StatusAbandoned  = "abandoned"
+	StatusCompleted  = "completed"
+	StatusReview     = "review"
+	StatusActive     = "active"
+	StatusParked     = "parked"
+	StatusPending    = "pending"
+	StatusStaged     = "staged"
+	StatusIncomplete = "incomplete"
+)
+
+// StatusClasses lists the status classes that a StatusMap may select.
+var StatusClasses = []string{
+	StatusClosed,
+	StatusAbandoned,
+	StatusCompleted,
+	StatusReview,
+	StatusActive,
+	StatusParked,
+	StatusPending,
+	StatusStaged,
+}
+
+// StatusRule maps a label, project status or close reason onto a status class.
+type StatusRule struct {
+	Match string `json:"match"` // matched case insensitively
+	Class string `json:"class"`
+}
+
+// StatusMap selects the status class of each task.
+//
+// Rules are applied in the following order, and the first match wins:
+//
+//  1. Closed tasks are matched by their close reason (e.g. "completed" or
+//     "not_planned") against Reasons, and are otherwise "closed". Labels and
+//     project status are ignored once a task is closed.
+//  2. Open tasks are matched against Labels, in the order of the rules, so
+//     the first rule matching any of the task's labels wins.
+//  3. Open tasks are then matched by the value of their ProjectField (e.g. the
+//     ProjectV2 "Status" field) against Project, again in rule order.
+//  4. Otherwise open tasks have no status class.
+type StatusMap struct {
+	Reasons      []StatusRule `json:"reasons"`
+	Labels       []StatusRule `json:"labels"`
+	ProjectField string       `json:"project_field"`
+	Project      []StatusRule `json:"project"`
+}
+
+// DefaultStatusMap maps the GitHub close reasons, some common workflow labels,
+// and the default ProjectV2 board columns.
+func DefaultStatusMap() *StatusMap {
+	return &StatusMap{
+		Reasons: []StatusRule{
+			{"completed", StatusCompleted},
+			{"not_planned", StatusAbandoned},
+		},
+		Labels: []StatusRule{
+			{"wontfix", StatusAbandoned},
+			{"blocked", StatusParked},
+			{"on hold", StatusParked},
+			{"in review", StatusReview},
+			{"in progress", StatusActive},
+			{"staged", StatusStaged},
+		},
+		ProjectField: "Status",
+		Project: []StatusRule{
+			{"Done", StatusCompleted},
+			{"In Review", StatusReview},
+			{"In Progress", StatusActive},
+			{"Blocked", StatusParked},
+			{"Todo", StatusPending},
+			{"Backlog", StatusPending},
+		},
+	}
+}
+
+// ReadStatusMap reads a JSON status map, and checks that it only selects
+// known status classes. Any sections missing from the JSON are left empty.
+func ReadStatusMap(reader io.Reader) (*StatusMap, error) {
+	var sm StatusMap
+	dec := json.NewDecoder(reader)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&sm); err != nil {
+		return nil, fmt.Errorf("bad status map: %w", err)
+	}
+	for _, rules := range [][]StatusRule{sm.Reasons, sm.Labels, sm.Project} {
+		for _, r := range rules {
+			if !isStatusClass(r.Class) {
+				return nil, fmt.Errorf("bad status map: unknown class %q for %q", r.Class, r.Match)
+			}
+		}
+	}
+	return &sm, nil
+}
+
+func isStatusClass(class string) bool {
+	for _, c := range StatusClasses {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+func matchRule(rules []StatusRule, values ...string) (string, bool) {
+	for _, r := range rules {
+		for _, v := range values {
+			if strings.EqualFold(r.Match, v) {
+				return r.Class, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Class selects the status class of a task, or "" if it has none.
+func (sm *StatusMap) Class(t *Task) string {
+	if t.IsClosed() {
+		if c, ok := matchRule(sm.Reasons, t.StateReason); ok {
+			return c
+		}
+		return StatusClosed
+	}
+	if c, ok := matchRule(sm.Labels, t.Labels...); ok {
+		return c
+	}
+	if len(sm.ProjectField) > 0 {
+		if v, ok := t.Fields[sm.ProjectField]; ok {
+			if c, ok := matchRule(sm.Project, v); ok {
+				return c
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,59 @@
+package taskgraph
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStatusMapPrecedence(t *testing.T) {
+	sm := DefaultStatusMap()
+
+	check := []struct {
+		task  Task
+		class string
+	}{
+		{Task{State: StateClosed}, StatusClosed},
+		{Task{State: StateClosed, StateReason: "completed"}, StatusCompleted},
+		{Task{State: StateClosed, StateReason: "NOT_PLANNED"}, StatusAbandoned},
+		// closing wins over labels and project status
+		{Task{State: StateClosed, StateReason: "completed", Labels: []string{"blocked"}}, StatusCompleted},
+		{Task{State: StateOpen}, ""},
+		{Task{State: StateOpen, Labels: []string{"bug", "In Review"}}, StatusReview},
+		// the first matching rule wins, regardless of the task's label order
+		{Task{State: StateOpen, Labels: []string{"in progress", "blocked"}}, StatusParked},
+		// labels win over project status
+		{Task{State: StateOpen, Labels: []string{"staged"}, Fields: map[string]string{"Status": "Todo"}}, StatusStaged},
+		{Task{State: StateOpen, Fields: map[string]string{"Status": "in progress"}}, StatusActive},
+		{Task{State: StateOpen, Fields: map[string]string{"Status": "Unknown"}}, ""},
+	}
+	for i, c := range check {
+		if got := sm.Class(&c.task); got != c.class {
+			t.Errorf("[%d] expected %q, got %q for %+v", i, c.class, got, c.task)
+		}
+	}
+}
+
+func TestReadStatusMap(t *testing.T) {
+	sm, err := ReadStatusMap(strings.NewReader(`{
+		"labels": [{"match": "doing", "class": "active"}],
+		"project_field": "Stage",
+		"project": [{"match": "QA", "class": "review"}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sm.Class(&Task{State: StateOpen, Labels: []string{"Doing"}}); got != StatusActive {
+		t.Errorf("expected active, got %q", got)
+	}
+	if got := sm.Class(&Task{State: StateOpen, Fields: map[string]string{"Stage": "qa"}}); got != StatusReview {
+		t.Errorf("expected review, got %q", got)
+	}
+	if got := sm.Class(&Task{State: StateClosed, StateReason: "completed"}); got != StatusClosed {
+		t.Errorf("expected closed without reason rules, got %q", got)
+	}
+
+	_, err = ReadStatusMap(strings.NewReader(`{"labels": [{"match": "x", "class": "purple"}]}`))
+	if err == nil {
+		t.Errorf("expected an unknown class to be rejected")
+	}
+}
@@ -0,0 +1,110 @@
+package taskgraph
+
+import (
+	htm "html"
+	"io"
+	"strings"
+)
+
+// svgPath draws a curve, as returned by layoutEdge.curve, as SVG path data.
+func svgPath(curve []point) string {
+	var b strings.Builder
+	for i, p := range curve {
+		switch {
+		case i == 0:
+			b.WriteString("M ")
+		case i%3 == 1:
+			b.WriteString(" C ")
+		default:
+			b.WriteString(", ")
+		}
+		b.WriteString(formatFloat(p.X) + " " + formatFloat(p.Y))
+	}
+	return b.String()
+}
+
+// ToSVG renders the graph as a static SVG image, laid out natively so that no
+// browser or external tool is needed. Nodes link to their issues.
+func (tg *TaskGraph) ToSVG(writer io.Writer, opts ...RenderOption) error {
+
+	cfg, err := newRender(opts...)
+	if err != nil {
+		return err
+	}
+	v, err := tg.view(cfg)
+	if err != nil {
+		return err
+	}
+	gl := v.layout(cfg.dir)
+	w := &errWriter{w: writer}
+
+	w.printf("<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%s\" height=\"%s\" viewBox=\"0 0 %s %s\" font-family=\"Helvetica, Arial, sans-serif\" font-size=\"12\">\n",
+		formatFloat(gl.Width), formatFloat(gl.Height), formatFloat(gl.Width), formatFloat(gl.Height))
+	w.printf("<title>%s</title>\n", htm.EscapeString(cfg.theme.Title))
+	w.printf(`<style>
+	.edge { fill: none; stroke: #555555; stroke-width: 1.2; }
+	.edge.back { stroke-dasharray: 4 3; }
+	.node rect { stroke: #555555; stroke-width: 1; }
+	.node.incomplete rect { stroke-dasharray: 5 5; }
+	.node .ref { font-size: 10px; fill-opacity: 0.75; }
+</style>
+<defs>
+	<marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="7" markerHeight="7" orient="auto-start-reverse">
+		<path d="M 0 0 L 10 5 L 0 10 z" fill="#555555"/>
+	</marker>
+</defs>
+<rect width="100%%" height="100%%" fill="#ffffff"/>
+`)
+
+	// output edges
+	w.printf("<g class=\"edges\">\n")
+	for _, e := range gl.Edges {
+		class := "edge"
+		if e.Back {
+			class += " back"
+		}
+		w.printf("\t<path class=\"%s\" d=\"%s\" marker-end=\"url(#arrow)\"", class, svgPath(e.curve(gl.Dir)))
+		if label := e.Label(); len(label) > 0 {
+			w.printf("><title>%s</title></path>\n", htm.EscapeString(label))
+		} else {
+			w.printf("/>\n")
+		}
+	}
+	w.printf("</g>\n")
+
+	// output nodes
+	w.printf("<g class=\"nodes\">\n")
+	for _, n := range gl.Nodes {
+		fill, text := nodeColours(n)
+		indent := "\t"
+		if len(n.URL) > 0 {
+			w.printf("\t<a href=\"%s\" target=\"_top\">\n", htm.EscapeString(n.URL))
+			indent = "\t\t"
+		}
+		class := "node"
+		if len(n.Class) > 0 {
+			class += " " + n.Class
+		}
+		w.printf("%s<g id=\"%s\" class=\"%s\" transform=\"translate(%s %s)\">\n", indent, n.ID, class, formatFloat(n.X), formatFloat(n.Y))
+		w.printf("%s\t<title>%s</title>\n", indent, htm.EscapeString(n.Key+": "+n.Label))
+		w.printf("%s\t<rect width=\"%s\" height=\"%s\" rx=\"6\" fill=\"%s\"/>\n", indent, formatFloat(n.W), formatFloat(n.H), fill)
+		for i, line := range n.Lines {
+			w.printf("%s\t<text x=\"%s\" y=\"%s\" fill=\"%s\">%s</text>\n", indent,
+				formatFloat(layoutPadding), formatFloat(layoutPadding+float64(i+1)*layoutLineHeight-4), text, htm.EscapeString(line))
+		}
+		w.printf("%s\t<text class=\"ref\" x=\"%s\" y=\"%s\" fill=\"%s\">%s</text>\n", indent,
+			formatFloat(layoutPadding), formatFloat(layoutPadding+float64(len(n.Lines)+1)*layoutLineHeight-4), text, htm.EscapeString(n.Key))
+		if x, y, _, h, filled := n.rollupBar(); n.Bar && filled > 0 {
+			w.printf("%s\t<rect class=\"rollup\" x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\" fill=\"%s\"/>\n", indent,
+				formatFloat(x-n.X), formatFloat(y-n.Y), formatFloat(filled), formatFloat(h), text)
+		}
+		w.printf("%s</g>\n", indent)
+		if len(n.URL) > 0 {
+			w.printf("\t</a>\n")
+		}
+	}
+	w.printf("</g>\n")
+
+	w.printf("</svg>\n")
+	return w.err
+}
@@ -0,0 +1,59 @@
+package taskgraph
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestToSVG(t *testing.T) {
+	tg := New()
+	addTask(tg, "o/r#1", `Release "one"`, StateOpen)
+	addTask(tg, "o/r#2", "Feature", StateClosed).StateReason = "not_planned"
+	addTask(tg, "o/s#3", "Docs", StateOpen).Labels = []string{"in progress"}
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/r#2"})
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/s#3"})
+	tg.AddEdge(Edge{From: "o/s#3", To: "o/s#4"})
+	tg.AddEdge(Edge{From: "o/r#2", To: "o/s#3", Kind: EdgeBlocks})
+	tg.Incomplete["o/s#4"] = &IssueRef{"o", "s", 4}
+	tg.AddEdge(Edge{From: "o/s#4", To: "o/r#1"})
+
+	for _, dir := range []string{"TB", "LR"} {
+		var buf bytes.Buffer
+		if err := tg.ToSVG(&buf, WithDirection(dir)); err != nil {
+			t.Fatal(err)
+		}
+		out := buf.String()
+
+		// well formed
+		dec := xml.NewDecoder(strings.NewReader(out))
+		for {
+			_, err := dec.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s: bad SVG: %v\n%s", dir, err, out)
+			}
+		}
+
+		for _, want := range []string{
+			`<a href="https://github.com/o/r/issues/1" target="_top">`,
+			`<title>o/r#1: Release &#34;one&#34;</title>`,
+			`class="node abandoned"`,
+			`fill="#222222"/>`,
+			`class="node incomplete"`,
+			`<path class="edge back" d="M `,
+			`<title>blocks</title>`,
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s: expected %q in:\n%s", dir, want, out)
+			}
+		}
+		if n := strings.Count(out, `marker-end="url(#arrow)"`); n != 5 {
+			t.Errorf("%s: expected 5 edges, got %d", dir, n)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package taskgraph
+
+import (
+	"time"
+)
+
+// Task states.
+const (
+	StateOpen   = "open"
+	StateClosed = "closed"
+)
+
+// Task kinds.
+const (
+	KindIssue       = "issue"
+	KindPullRequest = "pull_request"
+)
+
+// Task is a node of the task graph.
+//
+// Tasks are independent of the tracker that they were fetched from, and are
+// filled in by an adapter such as TaskFromGitHub. Renderers and analyses only
+// ever work with tasks.
+type Task struct {
+	*IssueRef
+
+	ID    string // canonical reference, e.g. owner/repo#123
+	URL   string // where the task can be viewed
+	Title string
+	Body  string
+	Kind  string // KindIssue or KindPullRequest
+
+	State       string // StateOpen or StateClosed
+	StateReason string // why the task is in its state, e.g. "completed" or "not_planned"
+
+	Labels    []string
+	Assignees []string
+
+	Milestone    string
+	MilestoneDue time.Time // zero if the milestone has no due date
+
+	Created time.Time
+	Updated time.Time
+	Closed  time.Time // zero if the task has never been closed
+
+	Fetched time.Time // when the task was retrieved from its tracker
+
+	// Fields holds any additional tracker specific fields, such as custom
+	// project fields.
+	Fields map[string]string
+}
+
+// IsClosed reports whether the task has been closed.
+func (t *Task) IsClosed() bool {
+	return t.State == StateClosed
+}
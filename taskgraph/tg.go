@@ -0,0 +1,540 @@
+package taskgraph
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v52/github"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	extast "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+)
+
+// -- github traversal
+
+var validGitHubID = regexp.MustCompile(`^(([a-zA-Z0-9-_]+)/(([a-zA-Z0-9-_]+/?)+))?#([0-9]+)$`)
+var validGitHubIssue = regexp.MustCompile(`^/([a-zA-Z0-9-_]+)/(([a-zA-Z0-9-_]+/?)+)/issues/([0-9]+)$`)
+
+// LevelTrace sits below slog.LevelDebug and covers the raw issue bodies,
+// markdown ASTs and response headers seen during a traversal.
+const LevelTrace = slog.LevelDebug - 4
+
+// discardHandler drops all log records.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (d discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return d }
+func (d discardHandler) WithGroup(string) slog.Handler           { return d }
+
+var _tgDiscard = slog.New(discardHandler{})
+
+const (
+	github_closed = "closed"
+	github_open = "open"
+)
+
+// IssueRef identifies an issue by its owner, repository and number.
+type IssueRef struct {
+	Owner  string
+	Repo   string
+	Number int
+}
+
+func (is *IssueRef) String() string {
+	sep := "/"
+	if len(is.Owner) == 0 || len(is.Repo) == 0 {
+		sep = ""
+	}
+	return fmt.Sprintf("%s%s%s#%d", is.Owner, sep, is.Repo, is.Number)
+}
+
+// ParseIssueRef parses an issue reference of the form owner/repo#123, or #123
+// in which case the owner and repo are left empty.
+func ParseIssueRef(s string) (*IssueRef, error) {
+	matched := validGitHubID.FindStringSubmatch(s)
+	if len(matched) <= 4 {
+		return nil, fmt.Errorf("bad issue reference: %s", s)
+	}
+	num, err := strconv.ParseInt(matched[5], 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("bad issue reference: %s: %w", s, err)
+	}
+	return &IssueRef{Owner: matched[2], Repo: matched[3], Number: int(num)}, nil
+}
+
+// DefaultWorkers is the number of concurrent issue fetches used when no
+// explicit worker count has been set.
+const DefaultWorkers = 10
+
+// TaskGraph is a directed graph of issues, linked by their tasklists.
+//
+// The zero value is an empty graph ready for use, although New should be
+// used in order to apply options.
+type TaskGraph struct {
+	Refs  map[string]*Task
+	Edges map[string][]string
+
+	// Incomplete holds the referenced issues that were not fetched because
+	// the traversal was interrupted.
+	Incomplete map[string]*IssueRef
+
+	// Roots lists the tasks from which the graph was accumulated.
+	Roots []string
+
+	attrs map[edgeKey]Edge
+
+	skip_closed bool
+	workers     int
+	fields      []string
+	log         *slog.Logger
+	progress    func(Progress)
+}
+
+func (tg *TaskGraph) logger() *slog.Logger {
+	if tg.log == nil {
+		return _tgDiscard
+	}
+	return tg.log
+}
+
+// Progress summarises the state of a traversal.
+type Progress struct {
+	Fetched int // issues fetched so far
+	Pending int // issues queued or in flight
+	Failed  int // issues that could not be fetched in full, e.g. their fields
+
+	// RateRemaining is the most recently reported number of remaining GitHub
+	// API requests, or -1 if unknown.
+	RateRemaining int
+}
+
+// tasklistItem is an issue referenced from a tasklist.
+type tasklistItem struct {
+	*IssueRef
+	checked bool
+}
+
+func (tg *TaskGraph) init() {
+	if tg.Refs == nil {
+		tg.Refs = make(map[string]*Task, 100)
+	}
+	if tg.Edges == nil {
+		tg.Edges = make(map[string][]string, 10)
+	}
+	if tg.Incomplete == nil {
+		tg.Incomplete = make(map[string]*IssueRef)
+	}
+	if tg.attrs == nil {
+		tg.attrs = make(map[edgeKey]Edge)
+	}
+}
+
+// IssueSource fetches individual issues. It is satisfied by the
+// github.IssuesService of a github.Client.
+type IssueSource interface {
+	Get(ctx context.Context, owner string, repo string, number int) (*github.Issue, *github.Response, error)
+}
+
+// FieldSource is implemented by issue sources that can also provide custom
+// fields, such as the fields of a GitHub ProjectV2.
+type FieldSource interface {
+	Fields(ctx context.Context, owner string, repo string, number int) (map[string]string, error)
+}
+
+// Accumulate walks the tasklists reachable from the given issues, fetching
+// them via the GitHub client. See AccumulateFrom.
+func (tg *TaskGraph) Accumulate(ctx context.Context, client *github.Client, is ...*IssueRef) error {
+	return tg.AccumulateFrom(ctx, &GitHubSource{Client: client}, is...)
+}
+
+// AccumulateFrom walks the tasklists reachable from the given issues, along
+// with the issues that block them (see BlockedByKey).
+//
+// Issues are fetched by a pool of workers fed from a single queue. Children are
+// queued as soon as their parent has been parsed, and each issue is only ever
+// queued once, regardless of how many parents refer to it.
+//
+// If the traversal is cancelled, or a fetch fails, the graph accumulated so far
+// is retained and the issues that were still queued or in flight are recorded
+// in Incomplete. Accumulating again resumes from those issues.
+func (tg *TaskGraph) AccumulateFrom(ctx context.Context, source IssueSource, is ...*IssueRef) error {
+	tg.init()
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	type Result struct {
+		trigger *IssueRef
+		issue   *github.Issue
+		items   []tasklistItem
+		fields  map[string]string
+		partial bool // the fields could not be fetched
+		fetched time.Time
+		rate    int
+		err     error
+	}
+
+	workers := tg.workers
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+
+	jobs := make(chan *IssueRef)
+	results := make(chan Result)
+
+	// start the workers
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for rr := range jobs {
+				issue, items, rate, err := tg.accumulateIssueRefs(ctx, source, rr)
+				var fields map[string]string
+				var ferr error
+				if err == nil {
+					fields, ferr = tg.accumulateFields(ctx, source, rr)
+				}
+				select {
+				case results <- Result{rr, issue, items, fields, ferr != nil, time.Now(), rate, err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	defer wg.Wait()
+	defer cancel()
+	defer close(jobs)
+
+	// seed the queue, skipping anything that we have already visited
+	queued := make(map[string]bool, len(tg.Refs)+len(is))
+	for nm := range tg.Refs {
+		queued[nm] = true
+	}
+	pending := make([]*IssueRef, 0, len(is))
+	enqueue := func(refs ...*IssueRef) {
+		for _, r := range refs {
+			nm := r.String()
+			if queued[nm] {
+				continue
+			}
+			queued[nm] = true
+			pending = append(pending, r)
+		}
+	}
+	enqueue(is...)
+	for _, r := range is {
+		if !slices.Contains(tg.Roots, r.String()) {
+			tg.Roots = append(tg.Roots, r.String())
+		}
+	}
+	for _, r := range tg.Incomplete {
+		// resume an earlier, interrupted, traversal
+		enqueue(r)
+	}
+
+	// dispatch until nothing is pending or in flight
+	inflight := make(map[string]*IssueRef, workers)
+	progress := Progress{RateRemaining: -1}
+	report := func() {
+		if tg.progress != nil {
+			progress.Pending = len(pending) + len(inflight)
+			tg.progress(progress)
+		}
+	}
+	stop := func(err error) error {
+		// remember the unexplored frontier so that partial results can be used
+		for _, r := range pending {
+			tg.Incomplete[r.String()] = r
+		}
+		for nm, r := range inflight {
+			tg.Incomplete[nm] = r
+		}
+		return err
+	}
+	for len(pending) > 0 || len(inflight) > 0 {
+		var dispatch chan *IssueRef
+		var next *IssueRef
+		if len(pending) > 0 {
+			dispatch = jobs
+			next = pending[0]
+		}
+
+		select {
+		case dispatch <- next:
+			pending = pending[1:]
+			inflight[next.String()] = next
+		case res := <-results:
+			if res.rate >= 0 {
+				progress.RateRemaining = res.rate
+			}
+			if res.err != nil {
+				if ctx.Err() != nil {
+					// the fetch was most likely abandoned due to the cancellation
+					return stop(ctx.Err())
+				}
+				tg.logger().Error("fetch failed", "issue", res.trigger, "err", res.err)
+				progress.Failed++
+				report()
+				return stop(res.err)
+			}
+			// update our nodes
+			nm := res.trigger.String()
+			delete(inflight, nm)
+			delete(tg.Incomplete, nm)
+			task := TaskFromGitHub(res.trigger, res.issue)
+			task.Fetched = res.fetched
+			task.Fields = res.fields
+			tg.Refs[nm] = task
+			// update our edges
+			if _, ok := tg.Edges[nm]; !ok {
+				tg.Edges[nm] = make([]string, 0, len(res.items))
+			}
+			for _, r := range res.items {
+				tg.AddEdge(Edge{From: nm, To: r.String(), Kind: EdgeTasklist, Checked: r.checked})
+				// extend our pending list
+				enqueue(r.IssueRef)
+			}
+			for _, r := range blockedBy(task) {
+				if !tg.HasEdge(r.String(), nm) {
+					tg.AddEdge(Edge{From: r.String(), To: nm, Kind: EdgeBlocks})
+				}
+				enqueue(r)
+			}
+			progress.Fetched++
+			if res.partial {
+				progress.Failed++
+			}
+			report()
+		case <-ctx.Done():
+			return stop(ctx.Err())
+		}
+	}
+
+	return nil
+}
+
+// Consider 
+// var iol github.IssueListOptions
+// iol.Filter = ""
+// could use the list endpoint to collect seed events
+// could use the issue-comment webhook to stay in sync
+// https://docs.github.com/en/webhooks-and-events/webhooks/webhook-events-and-payloads#issue_comment
+
+// accumulateFields fetches the requested custom fields of an issue, if the
+// source supports them. Failures are logged, and counted in the progress,
+// rather than stopping the traversal.
+func (tg *TaskGraph) accumulateFields(ctx context.Context, source IssueSource, is *IssueRef) (map[string]string, error) {
+	fs, ok := source.(FieldSource)
+	if !ok || len(tg.fields) == 0 {
+		return nil, nil
+	}
+	all, err := fs.Fields(ctx, is.Owner, is.Repo, is.Number)
+	if err != nil {
+		tg.logger().Warn("fetching fields failed", "issue", is.String(), "err", err)
+		return nil, err
+	}
+	fields := make(map[string]string, len(tg.fields))
+	for _, name := range tg.fields {
+		if v, ok := all[name]; ok {
+			fields[name] = v
+		}
+	}
+	return fields, nil
+}
+
+var x_ratelimit_remaining string
+
+func init() {
+	x_ratelimit_remaining = strings.ToLower("X-Ratelimit-Remaining")
+}
+
+func (tg *TaskGraph) accumulateIssueRefs(ctx context.Context, src IssueSource, is *IssueRef) (*github.Issue, []tasklistItem, int, error) {
+	lg := tg.logger().With("issue", is.String())
+	lg.Info("traversing")
+
+	issues := make([]tasklistItem, 0, 10)
+
+	issue, resp, err := src.Get(ctx, is.Owner, is.Repo, is.Number)
+	rate := -1
+	if resp != nil && resp.Response != nil && len(resp.Header.Get(x_ratelimit_remaining)) > 0 {
+		rate = resp.Rate.Remaining
+	}
+	if err != nil {
+		return nil, nil, rate, err
+	}
+
+	// log headers
+	var header http.Header
+	if resp != nil && resp.Response != nil {
+		header = resp.Header
+	}
+	lg.Log(ctx, LevelTrace, "github headers", "count", len(header))
+	for k, v := range header {
+		lg.Log(ctx, LevelTrace, "github header", "key", k)
+		if strings.HasPrefix(k, "X-") || strings.HasPrefix(k, "x-") {
+			for i, x := range v {
+				level := LevelTrace
+				if strings.ToLower(k) == x_ratelimit_remaining {
+					level = slog.LevelDebug
+				}
+				lg.Log(ctx, level, "github header", "key", k, "index", i, "value", x)
+			}
+		}
+	}
+	if issue == nil {
+		return nil, nil, rate, fmt.Errorf("nil issue for %v", is)
+	}
+
+	// check body
+	if issue.Body == nil || len(*issue.Body) == 0 {
+		lg.Debug("nil or empty body")
+		return issue, issues, rate, nil
+	}
+	lg.Log(ctx, LevelTrace, "issue body", "body", *issue.Body)
+
+	// check state
+	if tg.skip_closed && issue.GetState() == github_closed {
+		return issue, issues, rate, nil
+	}
+
+	// parse markdown
+	md := goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithParserOptions(
+			parser.WithAutoHeadingID(),
+		),
+		goldmark.WithRendererOptions(
+			html.WithHardWraps(),
+			html.WithXHTML(),
+		),
+	)
+	source := []byte(*issue.Body)
+	reader := text.NewReader(source)
+	rootAstNode := md.Parser().Parse(reader)
+	lg.Log(ctx, LevelTrace, "issue ast", "kind", rootAstNode.Kind())
+
+	// extract a part of the source (creates copies...)
+	captureLines := func(n ast.Node) *bytes.Buffer {
+		buf := bytes.Buffer{}
+		l := n.Lines().Len()
+		for i := 0; i < l; i++ {
+			line := n.Lines().At(i)
+			buf.Write(line.Value(source))
+		}
+		return &buf
+	}
+
+	carry := func(o string, r string, n int) IssueRef {
+		issue := IssueRef{o, r, n}
+		if len(issue.Owner) == 0 {
+			issue.Owner = is.Owner
+		}
+		if len(issue.Repo) == 0 {
+			issue.Repo = is.Repo
+		}
+		return issue
+	}
+
+	// parse the task list itself
+	parseTasklist := func(tasklistSource []byte) error {
+		tasklistReader := text.NewReader(tasklistSource)
+		tasklistRootNode := md.Parser().Parse(tasklistReader)
+		checked := false
+		ast.Walk(tasklistRootNode, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+			if entering {
+				lg.Log(ctx, LevelTrace, "tasklist ast", "kind", n.Kind(), "type", n.Type())
+				switch n.Kind() {
+				// track the check box state of each item
+				case ast.KindListItem:
+					checked = false
+				case extast.KindTaskCheckBox:
+					checked = n.(*extast.TaskCheckBox).IsChecked
+				// parse text references
+				case ast.KindText:
+					txt := n.(*ast.Text)
+					ref := (string)(txt.Text(tasklistSource))
+					lg.Log(ctx, LevelTrace, "ast fenced text", "text", ref)
+					matched := validGitHubID.FindStringSubmatch(ref)
+					if len(matched) > 4 {
+						lg.Log(ctx, LevelTrace, "ast fenced text: issue", "text", ref)
+						if len(matched) > 4 {
+							owner := matched[2]
+							repo := matched[3]
+							numtxt := matched[5]
+							num, _ := strconv.ParseInt(numtxt, 10, 32)
+							number := int(num)
+							issue := carry(owner, repo, number)
+							lg.Debug("next issue", "next", issue.String())
+							issues = append(issues, tasklistItem{&issue, checked})
+						}
+					}
+				// parse link references
+				case ast.KindAutoLink:
+					lnk := n.(*ast.AutoLink)
+					url, err := url.Parse((string)(lnk.URL(tasklistSource)))
+					if err != nil {
+						lg.Warn("ast fenced auto-url: bad url",
+							"url", (string)(lnk.URL(tasklistSource)), "err", err)
+						break
+					}
+					lg.Log(ctx, LevelTrace, "ast fenced auto-url", "url", url.String(), "host", url.Host, "path", url.Path)
+					if url.Host == "github.com" && strings.Contains(url.Path, "/issues/") {
+						matched := validGitHubIssue.FindStringSubmatch(url.Path)
+						lg.Log(ctx, LevelTrace, "ast fenced auto-url: issue", "url", url.String(), "host", url.Host, "path", url.Path)
+						if len(matched) > 4 {
+							owner := matched[1]
+							repo := matched[2]
+							numtxt := matched[4]
+							num, _ := strconv.ParseInt(numtxt, 10, 32)
+							number := int(num)
+							issue := carry(owner, repo, number)
+							lg.Debug("next issue", "next", issue.String())
+							issues = append(issues, tasklistItem{&issue, checked})
+						}
+					}
+				}
+			}
+			return ast.WalkContinue, nil
+		})
+		return nil
+	}
+
+	// walk the ast to find all fenced code blocks with a language of type '[tasklist]'
+	ast.Walk(rootAstNode, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering {
+			lg.Log(ctx, LevelTrace, "ast", "kind", n.Kind())
+			switch n.Kind() {
+			case ast.KindFencedCodeBlock:
+				nk := n.(*ast.FencedCodeBlock)
+				language := nk.Language(source)
+				// segment := nk.Info.Segment
+				// fmt.Fprintf(io.Discard, "ast fenced: %v %v\n", string(language), segment)
+				lg.Log(ctx, LevelTrace, "ast fenced", "language", string(language))
+				if string(language) == "[tasklist]" {
+					buf := captureLines(nk)
+					lg.Log(ctx, LevelTrace, "ast fenced block", "block", buf.String())
+					parseTasklist(buf.Bytes())
+				}
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+
+	return issue, issues, rate, nil
+}
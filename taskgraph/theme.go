@@ -0,0 +1,169 @@
+package taskgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Directions lists the flow directions of rendered graphs: top to bottom,
+// bottom to top, left to right and right to left.
+var Directions = []string{"TB", "BT", "LR", "RL"}
+
+// horizontal reports whether a direction flows across, rather than down.
+func horizontal(dir string) bool {
+	return dir == "LR" || dir == "RL"
+}
+
+// reversed reports whether a direction flows against the reading order.
+func reversed(dir string) bool {
+	return dir == "BT" || dir == "RL"
+}
+
+// Palette entries, over and above the status classes.
+const (
+	PaletteGroup    = "group"    // the fill of groups, e.g. repos
+	PaletteFrame    = "frame"    // the fill of the Mermaid frame
+	PaletteCritical = "critical" // the outline of the critical path
+)
+
+// Theme sets the look of rendered graphs.
+type Theme struct {
+	// Title heads the graph, and titles its document.
+	Title string `json:"title"`
+	// Direction is the flow direction of the graph, one of Directions,
+	// unless set WithDirection. Empty for top to bottom.
+	Direction string `json:"direction,omitempty"`
+	// Dark draws Mermaid graphs, and the HTML pages that show them, for a
+	// dark background.
+	Dark bool `json:"dark"`
+	// Frame names the Mermaid subgraph that holds the whole graph, or is
+	// empty for none.
+	Frame string `json:"frame"`
+	// Palette holds the fill colours, as "#rgb" or "#rrggbb", of the status
+	// classes, of groups (PaletteGroup) and of the frame (PaletteFrame), and
+	// the colour of the critical path (PaletteCritical).
+	Palette map[string]string `json:"palette"`
+	// Mermaid is Mermaid configuration, written as an %%{init}%% directive,
+	// e.g. {"flowchart": {"defaultRenderer": "elk", "curve": "basis"}}.
+	Mermaid map[string]any `json:"mermaid,omitempty"`
+}
+
+// DefaultTheme is the theme of rendered graphs, unless set WithTheme.
+func DefaultTheme() *Theme {
+	palette := make(map[string]string, len(classFills))
+	for class, fill := range classFills {
+		palette[class] = fill
+	}
+	return &Theme{
+		Title:   "Task Graph",
+		Frame:   "Tasks",
+		Palette: palette,
+	}
+}
+
+var themeColour = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// ReadTheme reads a JSON theme over the DefaultTheme, so that a theme need
+// only give the settings that it changes, and checks it.
+func ReadTheme(reader io.Reader) (*Theme, error) {
+	t := DefaultTheme()
+	dec := json.NewDecoder(reader)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(t); err != nil {
+		return nil, fmt.Errorf("bad theme: %w", err)
+	}
+	if err := t.validate(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *Theme) validate() error {
+	if len(t.Direction) > 0 && !isDirection(t.Direction) {
+		return fmt.Errorf("bad theme: unknown direction %q", t.Direction)
+	}
+	for k, colour := range t.Palette {
+		if !isStatusClass(k) && k != StatusIncomplete && k != PaletteGroup && k != PaletteFrame && k != PaletteCritical {
+			return fmt.Errorf("bad theme: unknown palette entry %q", k)
+		}
+		if !themeColour.MatchString(colour) {
+			return fmt.Errorf("bad theme: bad colour %q for %q", colour, k)
+		}
+	}
+	return nil
+}
+
+func isDirection(dir string) bool {
+	for _, d := range Directions {
+		if d == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// WithTheme sets the look of rendered graphs, in place of DefaultTheme.
+func WithTheme(t *Theme) RenderOption {
+	return func(r *render) {
+		r.theme = t
+	}
+}
+
+// fill is the palette colour of a status class, or other palette entry, or ""
+// if it has none.
+func (t *Theme) fill(k string) string {
+	if fill, ok := t.Palette[k]; ok {
+		return fill
+	}
+	switch {
+	case k == PaletteGroup && t.Dark:
+		return "#3a3a4a"
+	case k == PaletteGroup:
+		return "#eeeedd"
+	case k == PaletteFrame && t.Dark:
+		return "#1e1e1e"
+	case k == PaletteFrame:
+		return "#fff"
+	case k == PaletteCritical && t.Dark:
+		return "#ff5c5c"
+	case k == PaletteCritical:
+		return "#d00000"
+	}
+	return ""
+}
+
+// mermaidInit is the %%{init}%% directive of the theme, or "" if it needs
+// none.
+func (t *Theme) mermaidInit() (string, error) {
+	init := make(map[string]any, len(t.Mermaid)+1)
+	for k, v := range t.Mermaid {
+		init[k] = v
+	}
+	if _, ok := init["theme"]; !ok && t.Dark {
+		init["theme"] = "dark"
+	}
+	if len(init) == 0 {
+		return "", nil
+	}
+	b, err := json.Marshal(init)
+	if err != nil {
+		return "", fmt.Errorf("bad theme: %w", err)
+	}
+	// a directive ends at the first "}%%"
+	return "%%{init: " + strings.ReplaceAll(string(b), "}%%", "}%\\u0025") + "}%%\n", nil
+}
+
+// yamlPlain matches text that needs no quotes as a YAML value.
+var yamlPlain = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9 _.,()/-]*$`)
+
+// yamlString makes text safe as a YAML value, e.g. the title in front-matter.
+func yamlString(text string) string {
+	if yamlPlain.MatchString(text) {
+		return text
+	}
+	b, _ := json.Marshal(text) // JSON strings are YAML strings
+	return string(b)
+}
@@ -0,0 +1,116 @@
+package taskgraph
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReadTheme(t *testing.T) {
+	theme, err := ReadTheme(strings.NewReader(`{"title": "Plan", "palette": {"active": "#f80"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if theme.Title != "Plan" || theme.Frame != "Tasks" {
+		t.Errorf("expected the theme over the defaults, got %+v", theme)
+	}
+	if theme.fill(StatusActive) != "#f80" || theme.fill(StatusCompleted) != classFills[StatusCompleted] {
+		t.Errorf("expected the palette over the defaults, got %v", theme.Palette)
+	}
+
+	for _, bad := range []string{
+		`{"direction": "UP"}`,
+		`{"palette": {"nope": "#fff"}}`,
+		`{"palette": {"active": "orange"}}`,
+		`{"colour": "#fff"}`,
+	} {
+		if _, err := ReadTheme(strings.NewReader(bad)); err == nil {
+			t.Errorf("expected an error for %s", bad)
+		}
+	}
+}
+
+func TestMermaidTheme(t *testing.T) {
+	tg := New()
+	addTask(tg, "o/r#1", `Release "one"`, StateOpen)
+	addTask(tg, "o/r#2", "Feature", StateClosed).StateReason = "not_planned"
+	addTask(tg, "o/s#3", "Docs", StateOpen).Labels = []string{"in progress"}
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/r#2"})
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/s#3"})
+	tg.AddEdge(Edge{From: "o/s#3", To: "o/s#4"})
+	tg.AddEdge(Edge{From: "o/r#2", To: "o/s#3", Kind: EdgeBlocks})
+	tg.Incomplete["o/s#4"] = &IssueRef{"o", "s", 4}
+	theme, err := ReadTheme(strings.NewReader(`{
+		"title": "Plan: Q3",
+		"direction": "BT",
+		"dark": true,
+		"frame": "",
+		"palette": {"active": "#ff8800"},
+		"mermaid": {"flowchart": {"defaultRenderer": "elk"}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := tg.ToMermaid(&buf, WithTheme(theme)); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"---\ntitle: \"Plan: Q3\"\n---\n%%{init: {\"flowchart\":{\"defaultRenderer\":\"elk\"},\"theme\":\"dark\"}}%%\n\nflowchart BT\n",
+		"classDef active fill:#ff8800\n",
+		"classDef abandoned fill:#222222,color:#fff\n",
+		"classDef projects fill:#3a3a4a\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "tg_frame") {
+		t.Errorf("expected no frame in:\n%s", out)
+	}
+
+	// an explicit direction wins over the theme
+	buf.Reset()
+	if err := tg.ToMermaid(&buf, WithDirection("RL"), WithTheme(theme)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "flowchart RL\n") {
+		t.Errorf("expected RL in:\n%s", buf.String())
+	}
+}
+
+func TestReversedDirections(t *testing.T) {
+	tg := New()
+	addTask(tg, "o/r#1", `Release "one"`, StateOpen)
+	addTask(tg, "o/r#2", "Feature", StateClosed).StateReason = "not_planned"
+	addTask(tg, "o/s#3", "Docs", StateOpen).Labels = []string{"in progress"}
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/r#2"})
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/s#3"})
+	tg.AddEdge(Edge{From: "o/s#3", To: "o/s#4"})
+	tg.AddEdge(Edge{From: "o/r#2", To: "o/s#3", Kind: EdgeBlocks})
+	tg.Incomplete["o/s#4"] = &IssueRef{"o", "s", 4}
+
+	var buf bytes.Buffer
+	if err := tg.ToPlantUML(&buf, WithDirection("RL")); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"left to right direction\n", "tg_o_r_2 <-- tg_o_r_1\n"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected %q in:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := tg.ToD2(&buf, WithDirection("BT")); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "direction: up\n") {
+		t.Errorf("expected upwards flow in:\n%s", buf.String())
+	}
+
+	if err := tg.ToDot(&buf, WithDirection("UP")); err == nil {
+		t.Error("expected an error for an unknown direction")
+	}
+}
@@ -0,0 +1,183 @@
+package taskgraph
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WithDepth limits how many levels of subtasks the tree shows below the
+// roots. Zero, the default, shows every level.
+func WithDepth(depth int) RenderOption {
+	return func(r *render) {
+		r.depth = depth
+	}
+}
+
+// WithCollapseClosed stops the tree from expanding the subtasks of closed
+// tasks.
+func WithCollapseClosed(collapse bool) RenderOption {
+	return func(r *render) {
+		r.collapse = collapse
+	}
+}
+
+// WithASCII draws the tree with plain ASCII, for logs, rather than Unicode.
+func WithASCII(ascii bool) RenderOption {
+	return func(r *render) {
+		r.ascii = ascii
+	}
+}
+
+// WithColour colours the tree by status class, with ANSI escapes.
+func WithColour(colour bool) RenderOption {
+	return func(r *render) {
+		r.colour = colour
+	}
+}
+
+// WithHyperlinks links tasks in the tree to their issues, with OSC 8 escapes,
+// for terminals that support them.
+func WithHyperlinks(hyperlink bool) RenderOption {
+	return func(r *render) {
+		r.hyperlink = hyperlink
+	}
+}
+
+// treeColours are the ANSI colours of the status classes, chosen to read on
+// both light and dark terminals.
+var treeColours = map[string]string{
+	StatusClosed:     "2",
+	StatusAbandoned:  "90",
+	StatusCompleted:  "32",
+	StatusReview:     "91",
+	StatusActive:     "33",
+	StatusParked:     "35",
+	StatusPending:    "34",
+	StatusStaged:     "95",
+	StatusIncomplete: "2;3",
+}
+
+// treeChars are the glyphs that draw a tree.
+type treeChars struct {
+	branch, last, pipe, space string
+	open, busy, done, dropped string
+	unknown                   string
+}
+
+var (
+	treeUnicode = treeChars{"├── ", "└── ", "│   ", "    ", "○", "◐", "✔", "✘", "?"}
+	treeASCII   = treeChars{"|-- ", "`-- ", "|   ", "    ", "[ ]", "[~]", "[x]", "[-]", "[?]"}
+)
+
+// glyph shows the state of a task.
+func (c treeChars) glyph(n *viewNode) string {
+	switch {
+	case n.Task == nil:
+		return c.unknown
+	case n.Task.IsClosed() && n.Task.StateReason == "not_planned":
+		return c.dropped
+	case n.Task.IsClosed():
+		return c.done
+	case n.Class == StatusActive || n.Class == StatusReview:
+		return c.busy
+	}
+	return c.open
+}
+
+// terminalText strips control characters, so that titles cannot inject
+// escapes into the terminal.
+func terminalText(text string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\t':
+			return ' '
+		case r < 0x20 || (r >= 0x7f && r < 0xa0):
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// ToTree renders the graph as an indented tree for the terminal, in the style
+// of tree or cargo tree, following the tasklists from the roots in order. Each
+// task with subtasks shows how many of them are done. A task reachable from
+// several parents is expanded under the first, and elsewhere marked (*).
+//
+// The tree is plain Unicode text by default, see WithASCII, WithColour,
+// WithHyperlinks, WithDepth and WithCollapseClosed.
+func (tg *TaskGraph) ToTree(writer io.Writer, opts ...RenderOption) error {
+
+	cfg, err := newRender(opts...)
+	if err != nil {
+		return err
+	}
+	v, err := tg.view(cfg)
+	if err != nil {
+		return err
+	}
+	w := &errWriter{w: writer}
+	chars := treeUnicode
+	if cfg.ascii {
+		chars = treeASCII
+	}
+
+	line := func(item *treeItem) string {
+		ref := item.Key
+		if cfg.hyperlink && len(item.URL) > 0 {
+			ref = "\x1b]8;;" + terminalText(item.URL) + "\x1b\\" + ref + "\x1b]8;;\x1b\\"
+		}
+		text := chars.glyph(item.viewNode) + " " + ref
+		if item.Task != nil {
+			text += " " + terminalText(v.rollupLabel(item.viewNode, !cfg.ascii))
+		} else {
+			text += " (not fetched)"
+		}
+		if cfg.colour {
+			if c, ok := treeColours[item.Class]; ok {
+				text = "\x1b[" + c + "m" + text + "\x1b[0m"
+			}
+		}
+		if len(item.Children) > 0 {
+			done := 0
+			for _, c := range item.Children {
+				if c.Task != nil && c.Task.IsClosed() {
+					done++
+				}
+			}
+			text += fmt.Sprintf(" [%d/%d]", done, len(item.Children))
+		}
+		if item.Seen {
+			text += " (*)"
+		}
+		return text
+	}
+
+	var list func(items []*treeItem, prefix string)
+	list = func(items []*treeItem, prefix string) {
+		for i, item := range items {
+			branch, more := chars.branch, chars.pipe
+			if i == len(items)-1 {
+				branch, more = chars.last, chars.space
+			}
+			w.printf("%s%s%s\n", prefix, branch, line(item))
+			if !item.Collapsed {
+				list(item.Children, prefix+more)
+			}
+		}
+	}
+	expand := func(n *viewNode, depth int) bool {
+		if cfg.depth > 0 && depth >= cfg.depth {
+			return false
+		}
+		return !cfg.collapse || n.Task == nil || !n.Task.IsClosed()
+	}
+	for _, root := range tg.tree(v, expand) {
+		w.printf("%s\n", line(root))
+		if !root.Collapsed {
+			list(root.Children, "")
+		}
+	}
+
+	return w.err
+}
@@ -0,0 +1,66 @@
+package taskgraph
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestToTree(t *testing.T) {
+	tg := New()
+	addTask(tg, "o/r#1", `Release "one"`, StateOpen)
+	addTask(tg, "o/r#2", "Feature", StateClosed).StateReason = "not_planned"
+	addTask(tg, "o/s#3", "Docs", StateOpen).Labels = []string{"in progress"}
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/r#2"})
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/s#3"})
+	tg.AddEdge(Edge{From: "o/s#3", To: "o/s#4"})
+	tg.AddEdge(Edge{From: "o/r#2", To: "o/s#3", Kind: EdgeBlocks})
+	tg.Incomplete["o/s#4"] = &IssueRef{"o", "s", 4}
+	tg.Roots = []string{"o/r#1"}
+
+	var buf bytes.Buffer
+	if err := tg.ToTree(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `○ o/r#1 Release "one" [1/2]
+├── ✘ o/r#2 Feature [0/1]
+│   └── ◐ o/s#3 Docs [0/1]
+│       └── ? o/s#4 (not fetched)
+└── ◐ o/s#3 Docs (*)
+`
+	if got := buf.String(); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+
+	buf.Reset()
+	if err := tg.ToTree(&buf, WithASCII(true), WithCollapseClosed(true)); err != nil {
+		t.Fatal(err)
+	}
+	want = `[ ] o/r#1 Release "one" [1/2]
+|-- [-] o/r#2 Feature [0/1]
+` + "`-- [~] o/s#3 Docs [0/1]\n" + `    ` + "`-- [?] o/s#4 (not fetched)\n"
+	if got := buf.String(); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+
+	buf.Reset()
+	if err := tg.ToTree(&buf, WithDepth(1), WithColour(true), WithHyperlinks(true)); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"\x1b]8;;https://github.com/o/r/issues/1\x1b\\o/r#1\x1b]8;;\x1b\\",
+		"\x1b[90m✘ ",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%q", want, out)
+		}
+	}
+	if strings.Contains(out, "o/s#4") {
+		t.Errorf("expected depth to be limited in:\n%s", out)
+	}
+
+	if err := tg.ToTree(&buf, WithDepth(-1)); err == nil {
+		t.Error("expected an error for a negative depth")
+	}
+}
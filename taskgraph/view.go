@@ -0,0 +1,239 @@
+package taskgraph
+
+import (
+	"sort"
+)
+
+// viewNode is a task as it is to be drawn, whether or not it was fetched.
+type viewNode struct {
+	Key   string    // task reference
+	ID    string    // renderer node identifier
+	Ref   *IssueRef //
+	Task  *Task     // nil if the task was never fetched
+	Label string    // plain text, possibly of several lines
+	URL   string
+	Class string // status class, or "" if the task has none
+	Fill  string // fill colour of the status class, or "" if none
+	Group *viewGroup
+
+	Rollup *Rollup // nil if the task has nothing to roll up
+}
+
+// viewGroup is a set of nodes drawn together, e.g. as a subgraph or cluster.
+// Groups may nest, e.g. the repos of an owner.
+type viewGroup struct {
+	ID     string
+	Name   string
+	Nodes  []*viewNode
+	Groups []*viewGroup
+	Parent *viewGroup
+}
+
+// Path lists the identifiers of the group and of the groups that contain it,
+// outermost first.
+func (g *viewGroup) Path() []string {
+	if g == nil {
+		return nil
+	}
+	return append(g.Parent.Path(), g.ID)
+}
+
+type viewEdge struct {
+	Edge
+	From *viewNode
+	To   *viewNode
+}
+
+// Label is drawn on the edge, and names its kind when it is not simply a
+// tasklist item.
+func (e viewEdge) Label() string {
+	if e.Kind == EdgeTasklist {
+		return ""
+	}
+	return e.Kind
+}
+
+// view is the renderer independent preparation of a graph. It settles node
+// identities, grouping, filtering and styling so that every renderer draws
+// the same graph.
+type view struct {
+	Groups []*viewGroup // outermost groups
+	Loose  []*viewNode  // nodes outside of any group
+	Nodes  map[string]*viewNode
+	Edges  []viewEdge
+
+	Rollups  bool           // whether the rollups of the nodes are shown
+	Critical map[string]int // the position of each task on the critical path, if shown
+}
+
+// critical reports whether an edge is a step along the critical path.
+func (v *view) critical(e viewEdge) bool {
+	from, ok := v.Critical[e.From.Key]
+	to, ok2 := v.Critical[e.To.Key]
+	return ok && ok2 && to == from+1 && e.Kind == EdgeTasklist
+}
+
+// allGroups lists the groups and their subgroups, outermost first.
+func (v *view) allGroups() []*viewGroup {
+	var all []*viewGroup
+	var walk func(groups []*viewGroup)
+	walk = func(groups []*viewGroup) {
+		for _, g := range groups {
+			all = append(all, g)
+			walk(g.Groups)
+		}
+	}
+	walk(v.Groups)
+	return all
+}
+
+func (tg *TaskGraph) view(cfg *render) (*view, error) {
+	ids := newNodeIDs(len(tg.Refs) + len(tg.Incomplete))
+	v := &view{
+		Nodes:   make(map[string]*viewNode, len(tg.Refs)+len(tg.Incomplete)),
+		Rollups: cfg.rollup,
+	}
+
+	// repos that share a name across owners
+	owners := make(map[string]string)
+	shared := make(map[string]bool)
+	note := func(r *IssueRef) {
+		if was, ok := owners[r.Repo]; ok && was != r.Owner {
+			shared[r.Repo] = true
+		}
+		owners[r.Repo] = r.Owner
+	}
+	for _, t := range tg.Refs {
+		note(t.IssueRef)
+	}
+	for _, r := range tg.Incomplete {
+		note(r)
+	}
+
+	// place each node in its, possibly nested, group
+	groups := make(map[string]*viewGroup)
+	add := func(n *viewNode) {
+		v.Nodes[n.Key] = n
+		var g *viewGroup
+		path := "group"
+		for _, k := range tg.groupKeys(n, cfg.group, shared) {
+			path += "/" + k.key
+			sub, ok := groups[path]
+			if !ok {
+				sub = &viewGroup{Name: k.name, Parent: g}
+				groups[path] = sub
+			}
+			g = sub
+		}
+		if g == nil {
+			v.Loose = append(v.Loose, n)
+			return
+		}
+		g.Nodes = append(g.Nodes, n)
+		n.Group = g
+	}
+
+	rollups := tg.Rollup(cfg.estimates)
+	for _, k := range sortedKeys(tg.Refs) {
+		t := tg.Refs[k]
+		if cfg.filter != nil && !cfg.filter(t) {
+			continue
+		}
+		label, err := cfg.nodeLabel(t)
+		if err != nil {
+			return nil, err
+		}
+		n := &viewNode{
+			Key:   k,
+			ID:    ids.id(k),
+			Ref:   t.IssueRef,
+			Task:  t,
+			Label: label,
+			URL:   t.URL,
+			Class: cfg.status.Class(t),
+			Fill:  cfg.theme.fill(cfg.status.Class(t)),
+		}
+		if r, ok := rollups[k]; ok && r.Total > 0 {
+			n.Rollup = &r
+		}
+		add(n)
+	}
+	for _, k := range sortedKeys(tg.Incomplete) {
+		r := tg.Incomplete[k]
+		// we never fetched the issue, so we only know its reference
+		add(&viewNode{
+			Key:   k,
+			ID:    ids.id(k),
+			Ref:   r,
+			Label: r.String() + " ...",
+			URL:   githubURL(r),
+			Class: StatusIncomplete,
+			Fill:  cfg.theme.fill(StatusIncomplete),
+		})
+	}
+
+	// groups, and their subgroups, sorted by name
+	paths := sortedKeys(groups)
+	for _, path := range paths {
+		g := groups[path]
+		g.ID = ids.id(path)
+		if g.Parent == nil {
+			v.Groups = append(v.Groups, g)
+		} else {
+			g.Parent.Groups = append(g.Parent.Groups, g)
+		}
+	}
+	byName := func(gs []*viewGroup) {
+		sort.SliceStable(gs, func(i, j int) bool { return gs[i].Name < gs[j].Name })
+	}
+	byName(v.Groups)
+	for _, path := range paths {
+		byName(groups[path].Groups)
+	}
+
+	// only keep edges between visible nodes
+	for _, from := range sortedKeys(tg.Edges) {
+		src, ok := v.Nodes[from]
+		if !ok {
+			continue
+		}
+		for _, to := range tg.Edges[from] {
+			dst, ok := v.Nodes[to]
+			if !ok {
+				continue
+			}
+			v.Edges = append(v.Edges, viewEdge{tg.Edge(from, to), src, dst})
+		}
+	}
+
+	if cfg.critical {
+		v.Critical = make(map[string]int)
+		for i, ref := range tg.CriticalPath(cfg.estimates).Path {
+			v.Critical[ref] = i
+		}
+	}
+
+	// nodes in tasklist order from the roots, and edges in the order of their
+	// parents, so that output only changes when the graph does
+	order := make(map[*viewNode]int, len(v.Nodes))
+	var list func(items []*treeItem)
+	list = func(items []*treeItem) {
+		for _, item := range items {
+			if _, ok := order[item.viewNode]; !ok {
+				order[item.viewNode] = len(order)
+			}
+			list(item.Children)
+		}
+	}
+	list(tg.tree(v, nil))
+	byOrder := func(ns []*viewNode) {
+		sort.SliceStable(ns, func(i, j int) bool { return order[ns[i]] < order[ns[j]] })
+	}
+	byOrder(v.Loose)
+	for _, g := range groups {
+		byOrder(g.Nodes)
+	}
+	sort.SliceStable(v.Edges, func(i, j int) bool { return order[v.Edges[i].From] < order[v.Edges[j].From] })
+
+	return v, nil
+}
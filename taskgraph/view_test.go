@@ -0,0 +1,95 @@
+package taskgraph
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+// deterministicFixture builds the same graph, adding its tasks in the given
+// order.
+func deterministicFixture(refs []string) *TaskGraph {
+	tg := New()
+	tg.Roots = []string{"o/r#1"}
+	for _, ref := range refs {
+		addTask(tg, ref, "Task "+ref, StateOpen)
+	}
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/r#10"})
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/r#2"})
+	tg.AddEdge(Edge{From: "o/r#2", To: "o/a-b#3"})
+	tg.AddEdge(Edge{From: "o/r#10", To: "o/a_b#3"})
+	return tg
+}
+
+func TestDeterministicOutput(t *testing.T) {
+	renderers := map[string]func(*TaskGraph, io.Writer, ...RenderOption) error{
+		"mermaid":  (*TaskGraph).ToMermaid,
+		"dot":      (*TaskGraph).ToDot,
+		"plantuml": (*TaskGraph).ToPlantUML,
+		"d2":       (*TaskGraph).ToD2,
+		"svg":      (*TaskGraph).ToSVG,
+		"graphml":  (*TaskGraph).WriteGraphML,
+		"gexf":     (*TaskGraph).WriteGEXF,
+		"csv":      (*TaskGraph).WriteCSV,
+		"explorer": (*TaskGraph).WriteExplorer,
+	}
+	forward := []string{"o/r#1", "o/r#2", "o/r#10", "o/a-b#3", "o/a_b#3"}
+	backward := []string{"o/a_b#3", "o/a-b#3", "o/r#10", "o/r#2", "o/r#1"}
+	for name, render := range renderers {
+		var want bytes.Buffer
+		if err := render(deterministicFixture(forward), &want); err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 5; i++ {
+			var got bytes.Buffer
+			if err := render(deterministicFixture(backward), &got); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got.Bytes(), want.Bytes()) {
+				t.Errorf("%s: output differs between renders:\n%s\n%s", name, want.String(), got.String())
+				break
+			}
+		}
+	}
+}
+
+func TestViewOrder(t *testing.T) {
+	tg := deterministicFixture([]string{"o/r#1", "o/r#2", "o/r#10", "o/a-b#3", "o/a_b#3"})
+	cfg, err := newRender()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := tg.view(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names, nodes, edges []string
+	for _, g := range v.Groups {
+		names = append(names, g.Name)
+		for _, n := range g.Nodes {
+			nodes = append(nodes, n.ID)
+		}
+	}
+	for _, e := range v.Edges {
+		edges = append(edges, e.From.Key+">"+e.To.Key)
+	}
+	if got, want := strings.Join(names, " "), "a-b a_b r"; got != want {
+		t.Errorf("expected groups %q, got %q", want, got)
+	}
+	// tasklist order, with o/r#10 listed before o/r#2
+	if got, want := strings.Join(nodes, " "), "tg_o_a_b_3 tg_o_a_b_3_2 tg_o_r_1 tg_o_r_10 tg_o_r_2"; got != want {
+		t.Errorf("expected nodes %q, got %q", want, got)
+	}
+	if got, want := strings.Join(edges, " "), "o/r#1>o/r#10 o/r#1>o/r#2 o/r#10>o/a_b#3 o/r#2>o/a-b#3"; got != want {
+		t.Errorf("expected edges %q, got %q", want, got)
+	}
+
+	// adding a task leaves the identifiers of the others alone
+	addTask(tg, "o/r#0", "Task o/r#0", StateOpen)
+	tg.AddEdge(Edge{From: "o/r#1", To: "o/r#0"})
+	if v, _ = tg.view(cfg); v.Nodes["o/r#2"].ID != "tg_o_r_2" {
+		t.Errorf("expected a stable identifier, got %s", v.Nodes["o/r#2"].ID)
+	}
+}